@@ -0,0 +1,9 @@
+create table if not exists revocations (
+  jti text primary key,
+  kind text not null check (kind in ('TOKEN_KIND_REFRESH', 'TOKEN_KIND_ACCESS', 'TOKEN_KIND_PASSWORD_RESET', 'TOKEN_KIND_EMAIL_VERIFICATION')),
+  revoked_at timestamp not null default current_timestamp,
+  expires_at timestamp not null
+);
+
+create index if not exists idx_revocation_expires_at on revocations(expires_at);
+create index if not exists idx_token_revoked_at on tokens(revoked_at);
@@ -0,0 +1 @@
+alter table users add column tokens_not_before timestamp;
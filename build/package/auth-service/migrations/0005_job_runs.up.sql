@@ -0,0 +1,9 @@
+create table if not exists job_runs (
+  name text primary key,
+  slot timestamp not null,
+  owner text not null,
+  status text not null check (status in ('running', 'succeeded', 'failed')),
+  error text,
+  started_at timestamp not null,
+  finished_at timestamp
+);
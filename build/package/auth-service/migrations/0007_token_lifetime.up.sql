@@ -0,0 +1,2 @@
+alter table tokens add column remember_me boolean not null default false;
+alter table tokens add column max_expires_at timestamp;
@@ -0,0 +1,2 @@
+drop index if exists idx_password_history_user_id;
+drop table if exists password_history;
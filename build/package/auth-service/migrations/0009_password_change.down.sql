@@ -0,0 +1,2 @@
+alter table users drop column password_changed_at;
+alter table users drop column must_change_password;
@@ -0,0 +1,3 @@
+alter table tokens add column family_id text;
+
+create index if not exists idx_token_family_id on tokens(family_id);
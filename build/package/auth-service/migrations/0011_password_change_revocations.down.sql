@@ -0,0 +1,15 @@
+create table revocations_old (
+  jti text primary key,
+  kind text not null check (kind in ('TOKEN_KIND_REFRESH', 'TOKEN_KIND_ACCESS', 'TOKEN_KIND_PASSWORD_RESET', 'TOKEN_KIND_EMAIL_VERIFICATION')),
+  revoked_at timestamp not null default current_timestamp,
+  expires_at timestamp not null
+);
+
+insert into revocations_old (jti, kind, revoked_at, expires_at)
+select jti, kind, revoked_at, expires_at from revocations
+where kind <> 'TOKEN_KIND_PASSWORD_CHANGE';
+
+drop table revocations;
+alter table revocations_old rename to revocations;
+
+create index if not exists idx_revocation_expires_at on revocations(expires_at);
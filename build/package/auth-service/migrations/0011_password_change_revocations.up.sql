@@ -0,0 +1,14 @@
+create table revocations_new (
+  jti text primary key,
+  kind text not null check (kind in ('TOKEN_KIND_REFRESH', 'TOKEN_KIND_ACCESS', 'TOKEN_KIND_PASSWORD_RESET', 'TOKEN_KIND_EMAIL_VERIFICATION', 'TOKEN_KIND_PASSWORD_CHANGE')),
+  revoked_at timestamp not null default current_timestamp,
+  expires_at timestamp not null
+);
+
+insert into revocations_new (jti, kind, revoked_at, expires_at)
+select jti, kind, revoked_at, expires_at from revocations;
+
+drop table revocations;
+alter table revocations_new rename to revocations;
+
+create index if not exists idx_revocation_expires_at on revocations(expires_at);
@@ -0,0 +1,4 @@
+drop trigger if exists trigger_user_updated_at on users;
+drop function if exists set_updated_at;
+drop table if exists tokens;
+drop table if exists users;
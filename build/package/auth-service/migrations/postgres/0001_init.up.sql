@@ -0,0 +1,38 @@
+create table if not exists users (
+  user_id text primary key,
+  username text unique not null,
+  email text unique not null,
+  password_hash text not null,
+  created_at timestamptz not null,
+  updated_at timestamptz not null
+);
+
+create table if not exists tokens (
+  jti text primary key,
+  user_id text not null references users(user_id) on delete cascade,
+  kind text not null check (kind in ('TOKEN_KIND_REFRESH', 'TOKEN_KIND_PASSWORD_RESET', 'TOKEN_KIND_EMAIL_VERIFICATION')),
+  token_hash text not null,
+  issued_at timestamptz not null,
+  revoked_at timestamptz,
+  expires_at timestamptz not null,
+  created_at timestamptz not null default current_timestamp
+);
+
+create index if not exists idx_token_user_id on tokens(user_id);
+create index if not exists idx_token_expires_at on tokens(expires_at);
+create index if not exists idx_token_kind on tokens(kind);
+
+create index if not exists idx_user_email on users(email);
+create index if not exists idx_user_username on users(username);
+
+create or replace function set_updated_at() returns trigger as $$
+begin
+    new.updated_at = current_timestamp;
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger trigger_user_updated_at
+before update on users
+for each row
+execute function set_updated_at();
@@ -0,0 +1,2 @@
+drop index if exists idx_token_revoked_at;
+drop table if exists revocations;
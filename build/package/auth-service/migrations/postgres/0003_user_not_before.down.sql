@@ -0,0 +1 @@
+alter table users drop column tokens_not_before;
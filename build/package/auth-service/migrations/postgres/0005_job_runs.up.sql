@@ -0,0 +1,9 @@
+create table if not exists job_runs (
+  name text primary key,
+  slot timestamptz not null,
+  owner text not null,
+  status text not null check (status in ('running', 'succeeded', 'failed')),
+  error text,
+  started_at timestamptz not null,
+  finished_at timestamptz
+);
@@ -0,0 +1,6 @@
+drop index if exists idx_token_user_sessions;
+
+alter table tokens drop column last_used_at;
+alter table tokens drop column user_agent;
+alter table tokens drop column ip_address;
+alter table tokens drop column client_name;
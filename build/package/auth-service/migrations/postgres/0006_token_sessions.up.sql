@@ -0,0 +1,6 @@
+alter table tokens add column client_name text;
+alter table tokens add column ip_address text;
+alter table tokens add column user_agent text;
+alter table tokens add column last_used_at timestamptz;
+
+create index if not exists idx_token_user_sessions on tokens(user_id, issued_at, jti);
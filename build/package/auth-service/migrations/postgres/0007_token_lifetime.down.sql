@@ -0,0 +1,2 @@
+alter table tokens drop column max_expires_at;
+alter table tokens drop column remember_me;
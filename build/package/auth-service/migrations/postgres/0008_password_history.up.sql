@@ -0,0 +1,8 @@
+create table if not exists password_history (
+  id bigserial primary key,
+  user_id text not null references users(user_id) on delete cascade,
+  password_hash text not null,
+  created_at timestamptz not null default current_timestamp
+);
+
+create index if not exists idx_password_history_user_id on password_history(user_id, id);
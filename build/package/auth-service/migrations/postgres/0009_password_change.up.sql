@@ -0,0 +1,2 @@
+alter table users add column must_change_password boolean not null default false;
+alter table users add column password_changed_at timestamptz;
@@ -0,0 +1,2 @@
+drop index if exists idx_token_family_id;
+alter table tokens drop column family_id;
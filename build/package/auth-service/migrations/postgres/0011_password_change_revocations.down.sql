@@ -0,0 +1,5 @@
+delete from revocations where kind = 'TOKEN_KIND_PASSWORD_CHANGE';
+
+alter table revocations drop constraint if exists revocations_kind_check;
+alter table revocations add constraint revocations_kind_check
+  check (kind in ('TOKEN_KIND_REFRESH', 'TOKEN_KIND_ACCESS', 'TOKEN_KIND_PASSWORD_RESET', 'TOKEN_KIND_EMAIL_VERIFICATION'));
@@ -0,0 +1,6 @@
+package postgres
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
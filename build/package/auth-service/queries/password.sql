@@ -0,0 +1,17 @@
+-- name: AddPasswordHistory :exec
+insert into password_history (user_id, password_hash) values (?, ?);
+
+-- name: GetPasswordHistory :many
+select * from password_history
+where user_id = sqlc.arg(user_id)
+order by id desc
+limit sqlc.arg(size);
+
+-- name: PrunePasswordHistory :execrows
+delete from password_history
+where password_history.user_id = sqlc.arg(user_id) and password_history.id not in (
+  select h.id from password_history h
+  where h.user_id = sqlc.arg(user_id)
+  order by h.id desc
+  limit sqlc.arg(keep)
+);
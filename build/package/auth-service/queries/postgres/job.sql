@@ -0,0 +1,19 @@
+-- name: AcquireJobRun :execrows
+insert into job_runs (name, slot, owner, status, started_at)
+values (sqlc.arg(name), sqlc.arg(slot), sqlc.arg(owner), 'running', current_timestamp)
+on conflict (name) do update set
+  slot = excluded.slot,
+  owner = excluded.owner,
+  status = excluded.status,
+  error = null,
+  started_at = excluded.started_at,
+  finished_at = null
+where job_runs.slot < excluded.slot;
+
+-- name: FinishJobRun :execrows
+update job_runs
+set status = sqlc.arg(status), error = sqlc.arg(error), finished_at = current_timestamp
+where name = sqlc.arg(name) and slot = sqlc.arg(slot) and owner = sqlc.arg(owner);
+
+-- name: GetJobRun :one
+select * from job_runs where name = $1;
@@ -0,0 +1,39 @@
+-- name: CreateUser :exec
+insert into users (user_id, username, email, password_hash, created_at, updated_at, password_changed_at)
+values ($1, $2, $3, $4, current_timestamp, current_timestamp, current_timestamp);
+
+-- name: UpdateUser :one
+update users
+set
+  username = coalesce(nullif(sqlc.arg(username)::text, ''), username),
+  email = coalesce(nullif(sqlc.arg(email)::text, ''), email),
+  password_hash = coalesce(nullif(sqlc.arg(password_hash)::text, ''), password_hash),
+  password_changed_at = case when sqlc.arg(password_hash)::text = '' then password_changed_at else current_timestamp end,
+  must_change_password = case when sqlc.arg(password_hash)::text = '' then must_change_password else false end,
+  version = version + 1
+where
+  user_id = sqlc.arg(user_id)
+  and (version = sqlc.arg(expected_version) or sqlc.arg(expected_version)::bigint = 0)
+returning version;
+
+-- name: GetUserByID :one
+select * from users where user_id = $1;
+
+-- name: GetUserByEmail :one
+select * from users where email = $1;
+
+-- name: GetUserByUsername :one
+select * from users where username = $1;
+
+-- name: SetUserNotBefore :execrows
+update users set tokens_not_before = $1 where user_id = $2;
+
+-- name: SetMustChangePassword :execrows
+update users set must_change_password = $1 where user_id = $2;
+
+-- name: GetUserNotBefore :one
+select tokens_not_before from users where user_id = $1;
+
+-- name: GetUserCutoffs :many
+select user_id, tokens_not_before from users
+where tokens_not_before is not null and tokens_not_before > $1;
@@ -0,0 +1,29 @@
+-- name: CreateRevocation :exec
+insert into revocations (jti, kind, expires_at)
+values (?, ?, ?)
+on conflict (jti) do nothing;
+
+-- name: IsRevoked :one
+select exists (
+  select 1 from tokens
+  where tokens.jti = sqlc.arg(jti) and revoked_at is not null and expires_at > current_timestamp
+  union all
+  select 1 from revocations
+  where revocations.jti = sqlc.arg(jti) and expires_at > current_timestamp
+);
+
+-- name: GetRevokedTokens :many
+select jti, kind, expires_at from tokens
+where revoked_at is not null and expires_at > current_timestamp
+union all
+select jti, kind, expires_at from revocations
+where expires_at > current_timestamp;
+
+-- name: DeleteExpiredRevocations :execrows
+delete from revocations
+where jti in (
+  select r.jti from revocations r
+  where r.expires_at < sqlc.arg(before)
+  order by r.expires_at, r.jti
+  limit sqlc.arg(batch_size)
+);
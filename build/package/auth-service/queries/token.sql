@@ -1,6 +1,6 @@
 -- name: CreateToken :exec
-insert into tokens (jti, user_id, kind, token_hash, issued_at, expires_at)
-values (?, ?, ?, ?, ?, ?);
+insert into tokens (jti, user_id, kind, token_hash, issued_at, expires_at, client_name, ip_address, user_agent, remember_me, max_expires_at, family_id)
+values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetTokenByJTI :one
 select * from tokens
@@ -9,3 +9,57 @@ where jti = ? and expires_at > current_timestamp;
 -- name: GetTokensForUser :many
 select * from tokens
 where user_id = ? and expires_at > current_timestamp order by issued_at desc;
+
+-- name: GetTokensByFamily :many
+select * from tokens
+where family_id = ? and expires_at > current_timestamp order by issued_at desc;
+
+-- name: RevokeToken :execrows
+update tokens
+set revoked_at = coalesce(revoked_at, current_timestamp)
+where jti = ?;
+
+-- name: TouchToken :execrows
+update tokens set last_used_at = sqlc.arg(last_used_at) where jti = sqlc.arg(jti);
+
+-- name: ExtendToken :execrows
+update tokens
+set expires_at = sqlc.arg(expires_at), last_used_at = sqlc.arg(last_used_at)
+where jti = sqlc.arg(jti) and revoked_at is null and expires_at > current_timestamp;
+
+-- name: ListSessions :many
+select * from tokens
+where
+  user_id = sqlc.arg(user_id)
+  and kind = 'TOKEN_KIND_REFRESH'
+  and revoked_at is null
+  and expires_at > current_timestamp
+  and (issued_at < sqlc.arg(before_issued_at) or (issued_at = sqlc.arg(before_issued_at) and jti < sqlc.arg(before_jti)))
+order by issued_at desc, jti desc
+limit sqlc.arg(page_size);
+
+-- name: DeleteExpiredTokens :execrows
+delete from tokens
+where jti in (
+  select t.jti from tokens t
+  where t.expires_at < sqlc.arg(before)
+  order by t.expires_at, t.jti
+  limit sqlc.arg(batch_size)
+);
+
+-- name: ArchiveRevokedTokens :execrows
+insert into revocations (jti, kind, expires_at)
+select t.jti, t.kind, t.expires_at from tokens t
+where t.revoked_at is not null and t.revoked_at < sqlc.arg(before) and t.family_id is null
+order by t.revoked_at, t.jti
+limit sqlc.arg(batch_size)
+on conflict (jti) do nothing;
+
+-- name: DeleteRevokedTokens :execrows
+delete from tokens
+where jti in (
+  select t.jti from tokens t
+  where t.revoked_at is not null and t.revoked_at < sqlc.arg(before) and t.family_id is null
+  order by t.revoked_at, t.jti
+  limit sqlc.arg(batch_size)
+);
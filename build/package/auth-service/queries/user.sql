@@ -1,15 +1,20 @@
 -- name: CreateUser :exec
-insert into users (user_id, username, email, password_hash, created_at, updated_at)
-values (?, ?, ?, ?, current_timestamp, current_timestamp);
+insert into users (user_id, username, email, password_hash, created_at, updated_at, password_changed_at)
+values (?, ?, ?, ?, current_timestamp, current_timestamp, current_timestamp);
 
--- name: UpdateUser :exec
+-- name: UpdateUser :one
 update users
 set
   username = coalesce(nullif(sqlc.arg(username), ''), username),
   email = coalesce(nullif(sqlc.arg(email), ''), email),
-  password_hash = coalesce(nullif(sqlc.arg(password_hash), ''), password_hash)
+  password_hash = coalesce(nullif(sqlc.arg(password_hash), ''), password_hash),
+  password_changed_at = case when coalesce(sqlc.arg(password_hash), '') = '' then password_changed_at else current_timestamp end,
+  must_change_password = case when coalesce(sqlc.arg(password_hash), '') = '' then must_change_password else false end,
+  version = version + 1
 where
-  user_id = sqlc.arg(user_id);
+  user_id = sqlc.arg(user_id)
+  and (version = sqlc.arg(expected_version) or sqlc.arg(expected_version) = 0)
+returning version;
 
 -- name: GetUserByID :one
 select * from users where user_id = ?;
@@ -19,3 +24,16 @@ select * from users where email = ?;
 
 -- name: GetUserByUsername :one
 select * from users where username = ?;
+
+-- name: SetUserNotBefore :execrows
+update users set tokens_not_before = ? where user_id = ?;
+
+-- name: SetMustChangePassword :execrows
+update users set must_change_password = ? where user_id = ?;
+
+-- name: GetUserNotBefore :one
+select tokens_not_before from users where user_id = ?;
+
+-- name: GetUserCutoffs :many
+select user_id, tokens_not_before from users
+where tokens_not_before is not null and tokens_not_before > ?;
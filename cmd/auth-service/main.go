@@ -1,26 +1,90 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"log"
-	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/golang-migrate/migrate/v4"
 
+	"github.com/gebhn/auth-service/internal/cache"
 	"github.com/gebhn/auth-service/internal/config"
 	"github.com/gebhn/auth-service/internal/db"
+	"github.com/gebhn/auth-service/internal/janitor"
+	"github.com/gebhn/auth-service/internal/jobs"
+	"github.com/gebhn/auth-service/internal/snapshot"
+	"github.com/gebhn/auth-service/internal/store"
 )
 
 func main() {
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	c := db.NewLibsqlConn(config.GetTursoDbUrl(), config.GetTursoDbToken())
-	m := db.NewMigrator(c)
+	var c *sql.DB
+	var m *migrate.Migrate
+	replicas := []*sql.DB{}
 
+	switch config.GetDbDriver() {
+	case db.DriverPostgres:
+		c = db.NewPostgresConn(config.GetPostgresUrl())
+		m = db.NewPostgresMigrator(c)
+		for _, url := range config.GetDbReplicaUrls() {
+			replicas = append(replicas, db.NewPostgresConn(url))
+		}
+	default:
+		c = db.NewLibsqlConn(config.GetTursoDbUrl(), config.GetTursoDbToken())
+		m = db.NewMigrator(c)
+		for _, url := range config.GetDbReplicaUrls() {
+			replicas = append(replicas, db.NewLibsqlConn(url, config.GetTursoDbToken()))
+		}
+	}
+	for _, r := range replicas {
+		defer r.Close()
+	}
+
+	s := store.NewReplicaStore(config.GetDbDriver(), c, replicas...)
+	if len(replicas) > 0 {
+		go s.Run(ctx, config.GetDbReplicaHealthInterval())
+	}
+
+	var lease jobs.Lease
+	switch config.GetJobsLease() {
+	case "redis":
+		rc := cache.NewRedisCache(config.GetRedisAddress(), config.GetRedisPassword())
+		defer rc.Close()
+		lease = jobs.NewCacheLease(rc)
+	default:
+		lease = jobs.NewStoreLease(s)
+	}
+
+	scheduler := jobs.NewScheduler(lease, config.GetServiceName())
+
+	j := janitor.New(s, janitor.Config{
+		ExpiredRetention: config.GetExpiredRetention(),
+		RevokedRetention: config.GetRevokedRetention(),
+		BatchSize:        config.GetJanitorBatchSize(),
+	})
+	err := scheduler.Register(jobs.Job{
+		Name:     "janitor",
+		Schedule: jobs.Every(config.GetJanitorInterval()),
+		Run:      j.Purge,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g, err := snapshot.NewGeneratorFromConfig(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
+
 		if err := m.Down(); err != nil {
 			if !errors.Is(err, migrate.ErrNoChange) {
 				log.Fatal(err)
@@ -31,7 +95,11 @@ func main() {
 				log.Fatal(err)
 			}
 		}
+
+		go g.Run(ctx, config.GetRevocationSnapshotInterval())
+		scheduler.Run(ctx)
 	}()
 
-	<-stop
+	<-ctx.Done()
+	<-done
 }
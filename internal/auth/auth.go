@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrPasswordChangeRequired denies a restricted Principal anything but
+	// RestrictedMethods.
+	ErrPasswordChangeRequired = errors.New("password change required")
+)
+
+type Permission string
+
+// PermissionAdmin lets a caller act on any user rather than only itself.
+const PermissionAdmin Permission = "admin"
+
+// Principal is the verified caller of an RPC.
+type Principal struct {
+	UserID      string
+	Jti         string
+	Permissions []Permission
+	// Restricted is set for a TOKEN_KIND_PASSWORD_CHANGE token, which may
+	// only change the password of its own user.
+	Restricted bool
+}
+
+func (p *Principal) Has(perm Permission) bool {
+	return slices.Contains(p.Permissions, perm)
+}
+
+// Subject returns the user an RPC made by p acts on. An empty userID means
+// p itself, naming another user requires PermissionAdmin and an unrestricted
+// Principal.
+func (p *Principal) Subject(userID string) (string, error) {
+	if userID == "" || userID == p.UserID {
+		return p.UserID, nil
+	}
+	if p.Restricted || !p.Has(PermissionAdmin) {
+		return "", ErrPermissionDenied
+	}
+	return userID, nil
+}
+
+// Verifier authenticates the bearer token of a call. It returns an error
+// wrapping ErrUnauthenticated for a token which is malformed, expired or
+// revoked.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the Principal authenticated by the Interceptor.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubject_Self(t *testing.T) {
+	p := &Principal{UserID: "1"}
+
+	for _, userID := range []string{"", "1"} {
+		subject, err := p.Subject(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", subject)
+	}
+
+	_, err := p.Subject("2")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestSubject_Admin(t *testing.T) {
+	p := &Principal{UserID: "1", Permissions: []Permission{PermissionAdmin}}
+
+	subject, err := p.Subject("2")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", subject)
+}
+
+func TestSubject_Restricted(t *testing.T) {
+	p := &Principal{UserID: "1", Permissions: []Permission{PermissionAdmin}, Restricted: true}
+
+	subject, err := p.Subject("")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", subject)
+
+	_, err = p.Subject("2")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	p := &Principal{UserID: "1"}
+	got, ok := FromContext(NewContext(context.Background(), p))
+	assert.True(t, ok)
+	assert.Same(t, p, got)
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+// Methods are the RPCs which act on the account of their caller and so
+// require an authenticated Principal.
+var Methods = []string{
+	pb.AuthService_Logout_FullMethodName,
+	pb.AuthService_Update_FullMethodName,
+	pb.AuthService_ChangePassword_FullMethodName,
+	pb.AuthService_ListSessions_FullMethodName,
+	pb.AuthService_RevokeSession_FullMethodName,
+}
+
+// RestrictedMethods are the only RPCs a restricted Principal may call.
+var RestrictedMethods = []string{
+	pb.AuthService_ChangePassword_FullMethodName,
+}
+
+// subject is implemented by the requests of Methods which name the user they
+// act on.
+type subject interface {
+	GetUserId() string
+	SetUserId(string)
+}
+
+// flagger is implemented by requests which may flag a user as having to
+// change their password, which requires PermissionAdmin.
+type flagger interface {
+	HasMustChangePassword() bool
+}
+
+// Interceptor authenticates the bearer token of the authorization metadata
+// on the methods it guards, and puts the Principal in the context of the
+// handler. The user_id of the request is set to the Principal, or left as is
+// when the Principal may act on the user it names. Restricted Principals are
+// denied anything but RestrictedMethods.
+type Interceptor struct {
+	v       Verifier
+	methods map[string]bool
+}
+
+func NewInterceptor(v Verifier, methods ...string) *Interceptor {
+	i := &Interceptor{
+		v:       v,
+		methods: map[string]bool{},
+	}
+	for _, m := range methods {
+		i.methods[m] = true
+	}
+	return i
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !i.methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		p, err := i.authenticate(ctx)
+		if errors.Is(err, ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if err != nil {
+			log.Printf("auth: failed to verify token: %v", err)
+			return nil, status.Error(codes.Unavailable, "failed to verify token")
+		}
+
+		if p.Restricted && !slices.Contains(RestrictedMethods, info.FullMethod) {
+			return nil, status.Error(codes.PermissionDenied, ErrPasswordChangeRequired.Error())
+		}
+		if r, ok := req.(flagger); ok && r.HasMustChangePassword() && !p.Has(PermissionAdmin) {
+			return nil, status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+		}
+		if r, ok := req.(subject); ok {
+			userID, err := p.Subject(r.GetUserId())
+			if err != nil {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+			r.SetUserId(userID)
+		}
+		return handler(NewContext(ctx, p), req)
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context) (*Principal, error) {
+	token, err := bearer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.v.Verify(ctx, token)
+}
+
+func bearer(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	v := md.Get("authorization")
+	if len(v) != 1 {
+		return "", ErrUnauthenticated
+	}
+	scheme, token, ok := strings.Cut(v[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", ErrUnauthenticated
+	}
+	return token, nil
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+type verifierMock map[string]*Principal
+
+func (v verifierMock) Verify(ctx context.Context, token string) (*Principal, error) {
+	if token == "unavailable" {
+		return nil, errors.New("connection refused")
+	}
+	p, ok := v[token]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown token", ErrUnauthenticated)
+	}
+	return p, nil
+}
+
+var testVerifier = verifierMock{
+	"user":       {UserID: "1"},
+	"admin":      {UserID: "2", Permissions: []Permission{PermissionAdmin}},
+	"restricted": {UserID: "3", Restricted: true},
+}
+
+func callHelper(t *testing.T, method string, authorization string, req any) (*Principal, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	if authorization != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+	}
+
+	var got *Principal
+	handler := func(ctx context.Context, req any) (any, error) {
+		got, _ = FromContext(ctx)
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	_, err := NewInterceptor(testVerifier, Methods...).Unary()(ctx, req, info, handler)
+	return got, err
+}
+
+func TestInterceptor_Success(t *testing.T) {
+	req := pb.UpdateRequest_builder{Username: proto.String("username")}.Build()
+
+	p, err := callHelper(t, pb.AuthService_Update_FullMethodName, "Bearer user", req)
+	require.NoError(t, err)
+	assert.Equal(t, "1", p.UserID)
+	assert.Equal(t, "1", req.GetUserId())
+}
+
+func TestInterceptor_Unauthenticated(t *testing.T) {
+	req := &pb.ChangePasswordRequest{}
+
+	for _, authorization := range []string{"", "user", "Basic user", "Bearer ", "Bearer unknown"} {
+		_, err := callHelper(t, pb.AuthService_ChangePassword_FullMethodName, authorization, req)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), authorization)
+	}
+
+	_, err := callHelper(t, pb.AuthService_ChangePassword_FullMethodName, "Bearer unavailable", req)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestInterceptor_PermissionDenied(t *testing.T) {
+	req := pb.LogoutRequest_builder{UserId: proto.String("2")}.Build()
+
+	_, err := callHelper(t, pb.AuthService_Logout_FullMethodName, "Bearer user", req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestInterceptor_Admin(t *testing.T) {
+	req := pb.RevokeSessionRequest_builder{UserId: proto.String("1")}.Build()
+
+	p, err := callHelper(t, pb.AuthService_RevokeSession_FullMethodName, "bearer admin", req)
+	require.NoError(t, err)
+	assert.Equal(t, "2", p.UserID)
+	assert.Equal(t, "1", req.GetUserId())
+}
+
+func TestInterceptor_Restricted(t *testing.T) {
+	req := pb.ChangePasswordRequest_builder{OldPassword: proto.String("old"), NewPassword: proto.String("new")}.Build()
+
+	p, err := callHelper(t, pb.AuthService_ChangePassword_FullMethodName, "Bearer restricted", req)
+	require.NoError(t, err)
+	assert.Equal(t, "3", p.UserID)
+	assert.Equal(t, "3", req.GetUserId())
+
+	for _, method := range []string{pb.AuthService_Update_FullMethodName, pb.AuthService_ListSessions_FullMethodName} {
+		_, err = callHelper(t, method, "Bearer restricted", &pb.ListSessionsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), method)
+	}
+}
+
+func TestInterceptor_MustChangePassword(t *testing.T) {
+	req := pb.UpdateRequest_builder{UserId: proto.String("1"), MustChangePassword: proto.Bool(true)}.Build()
+
+	_, err := callHelper(t, pb.AuthService_Update_FullMethodName, "Bearer admin", req)
+	assert.NoError(t, err)
+
+	req = pb.UpdateRequest_builder{MustChangePassword: proto.Bool(false)}.Build()
+
+	_, err = callHelper(t, pb.AuthService_Update_FullMethodName, "Bearer user", req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestInterceptor_Unguarded(t *testing.T) {
+	p, err := callHelper(t, pb.AuthService_Login_FullMethodName, "", &pb.LoginRequest{})
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// Claims are the claims of a bearer token. The subject is the user the token
+// was issued to, the ID its jti and Kind the name of its pb.TokenKind.
+type Claims struct {
+	jwt.RegisteredClaims
+	Kind        string       `json:"kind"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+var _ Verifier = (*tokenVerifier)(nil)
+
+// tokenVerifier verifies HS256 signed tokens. A token is only accepted once
+// its signature and expiry are valid, its jti is not in the revoked list and
+// it was issued after any cutoff of its user.
+type tokenVerifier struct {
+	secret  []byte
+	list    revoked.List
+	cutoffs revoked.Cutoffs
+}
+
+func NewVerifier(secret []byte, l revoked.List, c revoked.Cutoffs) *tokenVerifier {
+	return &tokenVerifier{
+		secret:  secret,
+		list:    l,
+		cutoffs: c,
+	}
+}
+
+// Verify returns the Principal of an access token, or a restricted one for a
+// password change token. A revocation backend which cannot be reached is
+// reported as is rather than as ErrUnauthenticated.
+func (v *tokenVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, v.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+	if claims.Subject == "" || claims.ID == "" || claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: missing claims", ErrUnauthenticated)
+	}
+
+	p := &Principal{
+		UserID:      claims.Subject,
+		Jti:         claims.ID,
+		Permissions: claims.Permissions,
+	}
+	switch pb.TokenKind(pb.TokenKind_value[claims.Kind]) {
+	case pb.TokenKind_TOKEN_KIND_ACCESS:
+	case pb.TokenKind_TOKEN_KIND_PASSWORD_CHANGE:
+		p.Restricted = true
+	default:
+		return nil, fmt.Errorf("%w: unexpected token kind %q", ErrUnauthenticated, claims.Kind)
+	}
+
+	ok, err := v.list.Find(ctx, p.Jti)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, fmt.Errorf("%w: token revoked", ErrUnauthenticated)
+	}
+
+	ok, err = revoked.IssuedBeforeCutoff(ctx, v.cutoffs, p.UserID, claims.IssuedAt.Time)
+	if errors.Is(err, revoked.ErrNotFound) || errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown user", ErrUnauthenticated)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return nil, fmt.Errorf("%w: token revoked", ErrUnauthenticated)
+	}
+	return p, nil
+}
+
+func (v *tokenVerifier) key(*jwt.Token) (any, error) {
+	return v.secret, nil
+}
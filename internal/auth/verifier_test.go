@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+var testSecret = []byte("secret")
+
+func signHelper(t *testing.T, secret []byte, kind pb.TokenKind, jti string, issuedAt time.Time) string {
+	t.Helper()
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(config.GetTokenDuration(kind))),
+		},
+		Kind:        kind.String(),
+		Permissions: []Permission{PermissionAdmin},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	require.NoError(t, err)
+	return token
+}
+
+func newVerifierHelper(t *testing.T) (*tokenVerifier, revoked.List, revoked.Cutoffs) {
+	t.Helper()
+
+	s := store.NewMemoryStore()
+	err := s.CreateUser(context.Background(), sqlc.CreateUserParams{
+		UserID:       "1",
+		Username:     "username1",
+		Email:        "username1@mail.me",
+		PasswordHash: "pass",
+	})
+	require.NoError(t, err)
+
+	l := revoked.NewStoreRevokedList(s)
+	c := revoked.NewStoreCutoffs(s)
+	return NewVerifier(testSecret, l, c), l, c
+}
+
+func TestVerify_Access(t *testing.T) {
+	v, _, _ := newVerifierHelper(t)
+
+	p, err := v.Verify(context.Background(), signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "jti", time.Now()))
+	require.NoError(t, err)
+	assert.Equal(t, "1", p.UserID)
+	assert.Equal(t, "jti", p.Jti)
+	assert.True(t, p.Has(PermissionAdmin))
+	assert.False(t, p.Restricted)
+}
+
+func TestVerify_PasswordChange(t *testing.T) {
+	v, _, _ := newVerifierHelper(t)
+
+	p, err := v.Verify(context.Background(), signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_PASSWORD_CHANGE, "jti", time.Now()))
+	require.NoError(t, err)
+	assert.True(t, p.Restricted)
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	v, _, _ := newVerifierHelper(t)
+
+	tests := map[string]string{
+		"Malformed":    "not-a-token",
+		"Wrong Secret": signHelper(t, []byte("other"), pb.TokenKind_TOKEN_KIND_ACCESS, "jti", time.Now()),
+		"Expired":      signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "jti", time.Now().Add(-time.Hour)),
+		"Refresh Kind": signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_REFRESH, "jti", time.Now()),
+		"No Jti":       signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "", time.Now()),
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), token)
+			assert.ErrorIs(t, err, ErrUnauthenticated)
+		})
+	}
+}
+
+func TestVerify_Revoked(t *testing.T) {
+	v, l, _ := newVerifierHelper(t)
+	ctx := context.Background()
+	token := signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "jti", time.Now())
+
+	err := l.Create(ctx, "jti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	require.NoError(t, err)
+
+	_, err = v.Verify(ctx, token)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestVerify_Cutoff(t *testing.T) {
+	v, _, c := newVerifierHelper(t)
+	ctx := context.Background()
+	cutoff := time.Now().Add(-time.Minute)
+
+	err := c.Set(ctx, "1", cutoff)
+	require.NoError(t, err)
+
+	_, err = v.Verify(ctx, signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "before", cutoff.Add(-time.Minute)))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = v.Verify(ctx, signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "same", cutoff))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = v.Verify(ctx, signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "after", time.Now()))
+	assert.NoError(t, err)
+}
+
+func TestVerify_UnknownUser(t *testing.T) {
+	s := store.NewMemoryStore()
+	v := NewVerifier(testSecret, revoked.NewStoreRevokedList(s), revoked.NewStoreCutoffs(s))
+
+	_, err := v.Verify(context.Background(), signHelper(t, testSecret, pb.TokenKind_TOKEN_KIND_ACCESS, "jti", time.Now()))
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
@@ -0,0 +1,62 @@
+package bloom
+
+import (
+	"hash/maphash"
+	"math"
+	"sync/atomic"
+)
+
+// Filter is a fixed size bloom filter which is safe for concurrent use.
+type Filter struct {
+	bits  []atomic.Uint64
+	m     uint64
+	k     uint64
+	seeds [2]maphash.Seed
+}
+
+// New returns a Filter sized to hold n keys with a false positive
+// probability of p.
+func New(n uint, p float64) *Filter {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+
+	return &Filter{
+		bits:  make([]atomic.Uint64, (m+63)/64),
+		m:     m,
+		k:     k,
+		seeds: [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()},
+	}
+}
+
+// Add records key in the filter.
+func (f *Filter) Add(key string) {
+	h1, h2 := f.hash(key)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos/64].Or(1 << (pos % 64))
+	}
+}
+
+// Test reports whether key may have been added to the filter. A false result
+// is definite, a true result may be a false positive.
+func (f *Filter) Test(key string) bool {
+	h1, h2 := f.hash(key)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/64].Load()&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Filter) hash(key string) (uint64, uint64) {
+	return maphash.String(f.seeds[0], key), maphash.String(f.seeds[1], key) | 1
+}
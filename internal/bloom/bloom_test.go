@@ -0,0 +1,48 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTest_Added(t *testing.T) {
+	f := New(1000, 0.01)
+
+	for i := range 1000 {
+		f.Add("jti" + strconv.Itoa(i))
+	}
+	for i := range 1000 {
+		assert.True(t, f.Test("jti"+strconv.Itoa(i)))
+	}
+}
+
+func TestTest_NotAdded(t *testing.T) {
+	f := New(1000, 0.01)
+
+	assert.False(t, f.Test("jti"))
+}
+
+func TestTest_FalsePositiveRate(t *testing.T) {
+	f := New(1000, 0.01)
+
+	for i := range 1000 {
+		f.Add("jti" + strconv.Itoa(i))
+	}
+
+	fp := 0
+	for i := range 10000 {
+		if f.Test("other" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	assert.Less(t, float64(fp)/10000, 0.03)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	f := New(0, 2)
+
+	f.Add("jti")
+	assert.True(t, f.Test("jti"))
+}
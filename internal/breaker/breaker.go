@@ -0,0 +1,142 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Stats reports how often a Breaker tripped and how long it has spent
+// degraded, that is open or half-open.
+type Stats struct {
+	State    State
+	Trips    uint64
+	Degraded time.Duration
+}
+
+// Breaker is a circuit breaker which opens after a number of consecutive
+// failures and lets a single probe through once the cooldown has passed.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu             sync.Mutex
+	state          State
+	failures       int
+	probing        bool
+	openedAt       time.Time
+	degradedSince  time.Time
+	degradedBefore time.Duration
+	trips          uint64
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call should be attempted.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateClosed {
+		b.degradedBefore += b.now().Sub(b.degradedSince)
+	}
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case StateClosed:
+		b.failures++
+		if b.failures < b.threshold {
+			return
+		}
+		b.degradedSince = now
+		b.trips++
+	case StateOpen:
+		return
+	}
+	b.state = StateOpen
+	b.openedAt = now
+	b.probing = false
+}
+
+// Release ends a call let through by Allow whose outcome says nothing about
+// the backend, such as a malformed value or a caller giving up, so that a
+// half-open Breaker lets the next probe through.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	degraded := b.degradedBefore
+	if b.state != StateClosed {
+		degraded += b.now().Sub(b.degradedSince)
+	}
+	return Stats{
+		State:    b.state,
+		Trips:    b.trips,
+		Degraded: degraded,
+	}
+}
@@ -0,0 +1,110 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type clockMock struct {
+	t time.Time
+}
+
+func (c *clockMock) now() time.Time {
+	return c.t
+}
+
+func newBreakerHelper(t *testing.T) (*Breaker, *clockMock) {
+	t.Helper()
+
+	c := &clockMock{t: time.Now()}
+	b := New(3, time.Second*10)
+	b.now = c.now
+
+	return b, c
+}
+
+func TestAllow_Closed(t *testing.T) {
+	b, _ := newBreakerHelper(t)
+
+	b.Failure()
+	b.Failure()
+	assert.True(t, b.Allow())
+	assert.Equal(t, StateClosed, b.Stats().State)
+
+	b.Success()
+	b.Failure()
+	b.Failure()
+	assert.True(t, b.Allow())
+	assert.Equal(t, StateClosed, b.Stats().State)
+}
+
+func TestAllow_Open(t *testing.T) {
+	b, c := newBreakerHelper(t)
+
+	b.Failure()
+	b.Failure()
+	b.Failure()
+	assert.False(t, b.Allow())
+	assert.Equal(t, StateOpen, b.Stats().State)
+	assert.Equal(t, uint64(1), b.Stats().Trips)
+
+	c.t = c.t.Add(time.Second * 5)
+	assert.False(t, b.Allow())
+	assert.Equal(t, time.Second*5, b.Stats().Degraded)
+}
+
+func TestAllow_HalfOpen(t *testing.T) {
+	b, c := newBreakerHelper(t)
+
+	b.Failure()
+	b.Failure()
+	b.Failure()
+
+	c.t = c.t.Add(time.Second * 10)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+	assert.Equal(t, StateHalfOpen, b.Stats().State)
+
+	t.Run("Probe fails", func(t *testing.T) {
+		b.Failure()
+		assert.False(t, b.Allow())
+		assert.Equal(t, StateOpen, b.Stats().State)
+		assert.Equal(t, uint64(1), b.Stats().Trips)
+	})
+
+	t.Run("Probe succeeds", func(t *testing.T) {
+		c.t = c.t.Add(time.Second * 10)
+		assert.True(t, b.Allow())
+
+		b.Success()
+		assert.True(t, b.Allow())
+		assert.Equal(t, StateClosed, b.Stats().State)
+		assert.Equal(t, time.Second*20, b.Stats().Degraded)
+	})
+}
+
+func TestAllow_Released(t *testing.T) {
+	b, c := newBreakerHelper(t)
+
+	b.Failure()
+	b.Failure()
+	b.Failure()
+	c.t = c.t.Add(time.Second * 10)
+
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	b.Release()
+	assert.Equal(t, StateHalfOpen, b.Stats().State)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "closed", StateClosed.String())
+	assert.Equal(t, "open", StateOpen.String())
+	assert.Equal(t, "half-open", StateHalfOpen.String())
+	assert.Equal(t, "unknown", State(-1).String())
+}
@@ -0,0 +1,28 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidInput = errors.New("invalid input")
+
+// Handler receives the messages published on a channel.
+type Handler interface {
+	// OnSubscribe is called each time the subscription is established,
+	// including after a reconnect. Messages published while disconnected are
+	// lost, so handlers should recover any missed state here.
+	OnSubscribe(ctx context.Context)
+	OnMessage(ctx context.Context, message string)
+}
+
+type Broker interface {
+	io.Closer
+	Publish(ctx context.Context, channel string, message string) error
+	// Subscribe delivers messages published on channel to h until ctx is
+	// cancelled, reconnecting whenever the connection is lost.
+	Subscribe(ctx context.Context, channel string, h Handler) error
+}
+
+var _ Broker = (*redisBroker)(nil)
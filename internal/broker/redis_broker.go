@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	healthCheckInterval = time.Second * 30
+	minBackoff          = time.Millisecond * 100
+	maxBackoff          = time.Second * 10
+)
+
+type redisBroker struct {
+	c *redis.Client
+}
+
+func NewRedisBroker(addr string, password string) *redisBroker {
+	return &redisBroker{
+		c: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+		}),
+	}
+}
+
+func (r *redisBroker) Publish(ctx context.Context, channel string, message string) error {
+	if channel == "" || message == "" {
+		return ErrInvalidInput
+	}
+	return r.c.Publish(ctx, channel, message).Err()
+}
+
+func (r *redisBroker) Subscribe(ctx context.Context, channel string, h Handler) error {
+	if channel == "" || h == nil {
+		return ErrInvalidInput
+	}
+
+	ps := r.c.Subscribe(ctx, channel)
+	defer ps.Close()
+
+	// Receive does not observe cancellation, closing the subscription is
+	// what unblocks it.
+	stop := context.AfterFunc(ctx, func() { ps.Close() })
+	defer stop()
+
+	backoff := minBackoff
+	for {
+		msg, err := ps.ReceiveTimeout(ctx, healthCheckInterval)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				if err := ps.Ping(ctx); err == nil {
+					continue
+				}
+			}
+
+			log.Printf("broker: subscription to %s failed, retrying in %s: %v", channel, backoff, err)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = minBackoff
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				h.OnSubscribe(ctx)
+			}
+		case *redis.Message:
+			h.OnMessage(ctx, m.Payload)
+		}
+	}
+}
+
+func (r *redisBroker) Close() error {
+	return r.c.Close()
+}
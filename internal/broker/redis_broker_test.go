@@ -0,0 +1,178 @@
+package broker
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type brokerMock struct {
+	b *redisBroker
+	m *miniredis.Miniredis
+}
+
+type handlerMock struct {
+	subscribed chan struct{}
+	messages   chan string
+}
+
+var testBroker *brokerMock
+
+func TestMain(m *testing.M) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+
+	testBroker = &brokerMock{
+		b: NewRedisBroker(mr.Addr(), ""),
+		m: mr,
+	}
+
+	os.Exit(m.Run())
+}
+
+func newHandlerMock() *handlerMock {
+	return &handlerMock{
+		subscribed: make(chan struct{}, 8),
+		messages:   make(chan string, 8),
+	}
+}
+
+func (h *handlerMock) OnSubscribe(ctx context.Context) {
+	h.subscribed <- struct{}{}
+}
+
+func (h *handlerMock) OnMessage(ctx context.Context, message string) {
+	h.messages <- message
+}
+
+func subscribeHelper(t *testing.T, channel string) (*handlerMock, chan error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	h := newHandlerMock()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- testBroker.b.Subscribe(ctx, channel, h)
+	}()
+
+	select {
+	case <-h.subscribed:
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "timed out waiting for subscription")
+	}
+	return h, done
+}
+
+func TestPublish_Success(t *testing.T) {
+	h, _ := subscribeHelper(t, "channel")
+
+	err := testBroker.b.Publish(context.Background(), "channel", "message")
+	assert.NoError(t, err)
+
+	select {
+	case msg := <-h.messages:
+		assert.Equal(t, "message", msg)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for message")
+	}
+}
+
+func TestPublish_Fail(t *testing.T) {
+	testBroker.m.SetError("err")
+	defer testBroker.m.SetError("")
+
+	err := testBroker.b.Publish(context.Background(), "channel", "message")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "err")
+}
+
+func TestPublish_Invalid(t *testing.T) {
+	tc := []struct {
+		channel string
+		message string
+		label   string
+	}{
+		{
+			channel: "",
+			message: "message",
+			label:   "Missing Channel",
+		},
+		{
+			channel: "channel",
+			message: "",
+			label:   "Missing Message",
+		},
+	}
+
+	for _, c := range tc {
+		t.Run(c.label, func(t *testing.T) {
+			err := testBroker.b.Publish(context.Background(), c.channel, c.message)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), ErrInvalidInput.Error())
+		})
+	}
+}
+
+func TestSubscribe_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := newHandlerMock()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- testBroker.b.Subscribe(ctx, "channel", h)
+	}()
+	<-h.subscribed
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for subscription to end")
+	}
+}
+
+func TestSubscribe_Reconnect(t *testing.T) {
+	h, _ := subscribeHelper(t, "reconnect")
+
+	testBroker.m.Close()
+	require.NoError(t, testBroker.m.Restart())
+
+	select {
+	case <-h.subscribed:
+	case <-time.After(time.Second * 15):
+		require.FailNow(t, "timed out waiting for resubscription")
+	}
+
+	err := testBroker.b.Publish(context.Background(), "reconnect", "message")
+	assert.NoError(t, err)
+
+	select {
+	case msg := <-h.messages:
+		assert.Equal(t, "message", msg)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for message")
+	}
+}
+
+func TestSubscribe_Invalid(t *testing.T) {
+	err := testBroker.b.Subscribe(context.Background(), "", newHandlerMock())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidInput.Error())
+
+	err = testBroker.b.Subscribe(context.Background(), "channel", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidInput.Error())
+}
@@ -7,12 +7,26 @@ import (
 	"time"
 )
 
-var ErrInvalidInput = errors.New("invalid input")
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("not found")
+)
+
+// Entry is a single value written by SetMany.
+type Entry struct {
+	Key   string
+	Value string
+	Exp   time.Duration
+}
 
 type Cache interface {
 	io.Closer
 	Set(ctx context.Context, key string, value string, exp time.Duration) (string, error)
+	SetMany(ctx context.Context, entries []Entry) error
+	SetNX(ctx context.Context, key string, value string, exp time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, key string) error
+	Keys(ctx context.Context, pattern string) ([]string, error)
 }
 
 var _ Cache = (*redisCache)(nil)
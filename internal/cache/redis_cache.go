@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -27,11 +28,64 @@ func (r *redisCache) Set(ctx context.Context, key string, value string, exp time
 	return r.c.Set(ctx, key, value, exp).Result()
 }
 
+// SetMany writes every entry in a single pipelined round trip.
+func (r *redisCache) SetMany(ctx context.Context, entries []Entry) error {
+	for _, e := range entries {
+		if e.Key == "" || e.Value == "" || e.Exp <= 0 {
+			return ErrInvalidInput
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := r.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, e := range entries {
+			p.Set(ctx, e.Key, e.Value, e.Exp)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *redisCache) SetNX(ctx context.Context, key string, value string, exp time.Duration) (bool, error) {
+	if key == "" || value == "" || exp <= 0 {
+		return false, ErrInvalidInput
+	}
+	return r.c.SetNX(ctx, key, value, exp).Result()
+}
+
 func (r *redisCache) Get(ctx context.Context, key string) (string, error) {
 	if key == "" {
 		return "", ErrInvalidInput
 	}
-	return r.c.Get(ctx, key).Result()
+	v, err := r.c.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return v, err
+}
+
+// Del removes key, a key which does not exist is not an error.
+func (r *redisCache) Del(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrInvalidInput
+	}
+	return r.c.Del(ctx, key).Err()
+}
+
+func (r *redisCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, ErrInvalidInput
+	}
+	keys := []string{}
+	iter := r.c.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
 }
 
 func (r *redisCache) Close() error {
@@ -87,6 +87,100 @@ func TestSet_Invalid(t *testing.T) {
 	}
 }
 
+func TestSetMany_Success(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	defer testCache.m.FlushAll()
+
+	var err error
+	var v string
+
+	err = testCache.c.SetMany(context.Background(), []Entry{
+		{Key: "key1", Value: "value1", Exp: time.Second * 5},
+		{Key: "key2", Value: "value2", Exp: time.Second * 10},
+	})
+	assert.NoError(t, err)
+
+	v, err = testCache.c.Get(context.Background(), "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, "value1", v)
+
+	v, err = testCache.c.Get(context.Background(), "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, "value2", v)
+
+	assert.Equal(t, time.Second*5, testCache.m.TTL("key1"))
+	assert.Equal(t, time.Second*10, testCache.m.TTL("key2"))
+
+	err = testCache.c.SetMany(context.Background(), nil)
+	assert.NoError(t, err)
+}
+
+func TestSetMany_Fail(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	testCache.m.SetError("err")
+	defer testCache.m.SetError("")
+
+	err := testCache.c.SetMany(context.Background(), []Entry{
+		{Key: "key1", Value: "value1", Exp: time.Second * 5},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "err")
+}
+
+func TestSetMany_Invalid(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	defer testCache.m.FlushAll()
+
+	err := testCache.c.SetMany(context.Background(), []Entry{
+		{Key: "key1", Value: "value1", Exp: time.Second * 5},
+		{Key: "key2", Value: "value2", Exp: 0},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidInput.Error())
+
+	_, err = testCache.c.Get(context.Background(), "key1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSetNX_Success(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	defer testCache.m.FlushAll()
+
+	var err error
+	var ok bool
+	var v string
+
+	ok, err = testCache.c.SetNX(context.Background(), "key", "value", time.Second*5)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = testCache.c.SetNX(context.Background(), "key", "other", time.Second*5)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	v, err = testCache.c.Get(context.Background(), "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+}
+
+func TestSetNX_Fail(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	testCache.m.SetError("err")
+	defer testCache.m.SetError("")
+
+	_, err := testCache.c.SetNX(context.Background(), "key", "value", time.Second*5)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "err")
+}
+
+func TestSetNX_Invalid(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+
+	_, err := testCache.c.SetNX(context.Background(), "", "value", time.Second*5)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidInput.Error())
+}
+
 func TestGet_Success(t *testing.T) {
 	testCache.m.FastForward(time.Hour * 24)
 
@@ -120,6 +214,15 @@ func TestGet_Fail(t *testing.T) {
 	assert.Contains(t, err.Error(), "err")
 }
 
+func TestGet_NotFound(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	defer testCache.m.FlushAll()
+
+	v, err := testCache.c.Get(context.Background(), "does-not-exist")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, v)
+}
+
 func TestGet_Invalid(t *testing.T) {
 	testCache.m.FastForward(time.Hour * 24)
 
@@ -132,3 +235,74 @@ func TestGet_Invalid(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), ErrInvalidInput.Error())
 }
+
+func TestDel_Success(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+
+	_, err := testCache.c.Set(context.Background(), "key1", "value1", time.Second*5)
+	assert.NoError(t, err)
+
+	err = testCache.c.Del(context.Background(), "key1")
+	assert.NoError(t, err)
+
+	_, err = testCache.c.Get(context.Background(), "key1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = testCache.c.Del(context.Background(), "does-not-exist")
+	assert.NoError(t, err)
+}
+
+func TestDel_Fail(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	testCache.m.SetError("err")
+	defer testCache.m.SetError("")
+
+	err := testCache.c.Del(context.Background(), "key1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "err")
+}
+
+func TestDel_Invalid(t *testing.T) {
+	err := testCache.c.Del(context.Background(), "")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestKeys_Success(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	defer testCache.m.FlushAll()
+
+	var err error
+	var keys []string
+
+	_, err = testCache.c.Set(context.Background(), "prefix:key1", "value1", time.Second*5)
+	assert.NoError(t, err)
+
+	_, err = testCache.c.Set(context.Background(), "prefix:key2", "value2", time.Second*5)
+	assert.NoError(t, err)
+
+	_, err = testCache.c.Set(context.Background(), "other:key3", "value3", time.Second*5)
+	assert.NoError(t, err)
+
+	keys, err = testCache.c.Keys(context.Background(), "prefix:*")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"prefix:key1", "prefix:key2"}, keys)
+}
+
+func TestKeys_Fail(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+	testCache.m.SetError("err")
+	defer testCache.m.SetError("")
+
+	keys, err := testCache.c.Keys(context.Background(), "prefix:*")
+	assert.Error(t, err)
+	assert.Nil(t, keys)
+	assert.Contains(t, err.Error(), "err")
+}
+
+func TestKeys_Invalid(t *testing.T) {
+	testCache.m.FastForward(time.Hour * 24)
+
+	_, err := testCache.c.Keys(context.Background(), "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidInput.Error())
+}
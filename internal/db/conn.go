@@ -1,12 +1,20 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"log"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/tursodatabase/libsql-client-go/libsql"
 )
 
+const (
+	DriverLibsql   = "libsql"
+	DriverPostgres = "postgres"
+)
+
 func NewLibsqlConn(connectionString, authToken string) *sql.DB {
 	opts := []libsql.Option{}
 	if authToken != "" {
@@ -18,5 +26,47 @@ func NewLibsqlConn(connectionString, authToken string) *sql.DB {
 		log.Fatal(err)
 	}
 
-	return sql.OpenDB(driver)
+	return sql.OpenDB(foreignKeysConnector{driver})
+}
+
+// foreignKeysConnector enables foreign keys on every connection it opens,
+// SQLite leaves them off by default and only per connection.
+type foreignKeysConnector struct {
+	driver.Connector
+}
+
+func (c foreignKeysConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := enableForeignKeys(ctx, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func enableForeignKeys(ctx context.Context, conn driver.Conn) error {
+	const query = "pragma foreign_keys = on"
+
+	if e, ok := conn.(driver.ExecerContext); ok {
+		_, err := e.ExecContext(ctx, query, nil)
+		return err
+	}
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+func NewPostgresConn(connectionString string) *sql.DB {
+	c, err := sql.Open("pgx", connectionString)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return c
 }
@@ -5,12 +5,14 @@ import (
 	"log"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	"github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
 
 	"github.com/gebhn/auth-service/build/package/auth-service/migrations"
+	"github.com/gebhn/auth-service/build/package/auth-service/migrations/postgres"
 )
 
 func NewMigrator(conn *sql.DB) *migrate.Migrate {
@@ -30,3 +32,21 @@ func NewMigrator(conn *sql.DB) *migrate.Migrate {
 	}
 	return m
 }
+
+func NewPostgresMigrator(conn *sql.DB) *migrate.Migrate {
+	driver, err := pgx.WithInstance(conn, &pgx.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := iofs.New(postgres.FS, ".")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "pgx5", driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return m
+}
@@ -0,0 +1,131 @@
+package janitor
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// Config decides how long rows are kept around after they stopped mattering.
+type Config struct {
+	// ExpiredRetention is how long expired tokens and revocations are kept.
+	ExpiredRetention time.Duration
+	// RevokedRetention is how long revoked tokens are kept in full. They
+	// remain revoked until they expire, but only as a revocation.
+	RevokedRetention time.Duration
+	// BatchSize bounds the rows deleted by a single transaction.
+	BatchSize int
+}
+
+// Stats reports how many rows a Janitor purged since it was created, the rows
+// of each Purge are also logged.
+type Stats struct {
+	ExpiredTokens      uint64
+	RevokedTokens      uint64
+	ExpiredRevocations uint64
+}
+
+// Janitor deletes expired tokens, expired revocations and long revoked tokens
+// in bounded batches. It is meant to run as a job, so that only one instance
+// purges at a time.
+type Janitor struct {
+	s   store.Store
+	cfg Config
+	now func() time.Time
+
+	expiredTokens      atomic.Uint64
+	revokedTokens      atomic.Uint64
+	expiredRevocations atomic.Uint64
+}
+
+func New(s store.Store, cfg Config) *Janitor {
+	cfg.BatchSize = max(cfg.BatchSize, 1)
+	return &Janitor{
+		s:   s,
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Purge deletes every row past its retention, one batch per transaction so
+// that no transaction holds its locks for long, and logs how many rows were
+// deleted even when it fails partway.
+func (j *Janitor) Purge(ctx context.Context) error {
+	before := j.Stats()
+	err := j.purge(ctx)
+	after := j.Stats()
+
+	log.Printf("janitor: purged %d expired tokens, %d revoked tokens and %d expired revocations",
+		after.ExpiredTokens-before.ExpiredTokens,
+		after.RevokedTokens-before.RevokedTokens,
+		after.ExpiredRevocations-before.ExpiredRevocations,
+	)
+	return err
+}
+
+func (j *Janitor) purge(ctx context.Context) error {
+	now := j.now()
+	expired := now.Add(-j.cfg.ExpiredRetention)
+	revoked := now.Add(-j.cfg.RevokedRetention)
+	size := int64(j.cfg.BatchSize)
+
+	err := j.batches(ctx, &j.expiredTokens, func(s store.Store) (int64, error) {
+		return s.DeleteExpiredTokens(ctx, sqlc.DeleteExpiredTokensParams{Before: expired, BatchSize: size})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Revoked tokens are archived as revocations first, so that they keep
+	// being rejected until they expire. Tokens of a family are left to
+	// expire instead, reuse detection needs their family.
+	err = j.batches(ctx, &j.revokedTokens, func(s store.Store) (int64, error) {
+		p := sqlc.ArchiveRevokedTokensParams{Before: &revoked, BatchSize: size}
+		if _, err := s.ArchiveRevokedTokens(ctx, p); err != nil {
+			return 0, err
+		}
+		return s.DeleteRevokedTokens(ctx, sqlc.DeleteRevokedTokensParams(p))
+	})
+	if err != nil {
+		return err
+	}
+
+	return j.batches(ctx, &j.expiredRevocations, func(s store.Store) (int64, error) {
+		return s.DeleteExpiredRevocations(ctx, sqlc.DeleteExpiredRevocationsParams{Before: expired, BatchSize: size})
+	})
+}
+
+// batches runs fn in its own transaction until it deletes less than a full
+// batch, adding the deleted rows to counter.
+func (j *Janitor) batches(ctx context.Context, counter *atomic.Uint64, fn func(s store.Store) (int64, error)) error {
+	for {
+		var n int64
+		err := j.s.ExecTx(ctx, func(s store.Store) error {
+			var err error
+			n, err = fn(s)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		counter.Add(uint64(n))
+		if n < int64(j.cfg.BatchSize) {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+func (j *Janitor) Stats() Stats {
+	return Stats{
+		ExpiredTokens:      j.expiredTokens.Load(),
+		RevokedTokens:      j.revokedTokens.Load(),
+		ExpiredRevocations: j.expiredRevocations.Load(),
+	}
+}
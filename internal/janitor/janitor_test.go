@@ -0,0 +1,136 @@
+package janitor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// newJanitorHelper returns a Janitor whose clock runs a day ahead, so that
+// every token created now is past an hour of retention.
+func newJanitorHelper(t *testing.T, s store.Store, batchSize int) *Janitor {
+	t.Helper()
+
+	j := New(s, Config{
+		ExpiredRetention: time.Hour,
+		RevokedRetention: time.Hour,
+		BatchSize:        batchSize,
+	})
+	j.now = func() time.Time { return time.Now().Add(time.Hour * 24) }
+	return j
+}
+
+func insertTokensHelper(t *testing.T, s store.Store, n int, expiresIn time.Duration) []string {
+	t.Helper()
+
+	err := s.CreateUser(context.Background(), sqlc.CreateUserParams{
+		UserID:       "1",
+		Username:     "username1",
+		Email:        "username1@mail.me",
+		PasswordHash: "pass",
+	})
+	if err != nil {
+		require.ErrorIs(t, err, store.ErrConflict)
+	}
+
+	jtis := make([]string, n)
+	for i := range jtis {
+		jtis[i] = fmt.Sprintf("jti-%d-%s", i, expiresIn)
+		err := s.CreateToken(context.Background(), sqlc.CreateTokenParams{
+			Jti:       jtis[i],
+			UserID:    "1",
+			Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+			TokenHash: "hash",
+			IssuedAt:  time.Now(),
+			ExpiresAt: time.Now().Add(expiresIn),
+		})
+		require.NoError(t, err)
+	}
+	return jtis
+}
+
+func TestPurge_Expired(t *testing.T) {
+	s := store.NewMemoryStore()
+	j := newJanitorHelper(t, s, 2)
+
+	expired := insertTokensHelper(t, s, 5, time.Hour)
+	live := insertTokensHelper(t, s, 1, time.Hour*48)
+
+	err := j.Purge(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), j.Stats().ExpiredTokens)
+
+	for _, jti := range expired {
+		_, err := s.GetTokenByJTI(context.Background(), jti)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	}
+	_, err = s.GetTokenByJTI(context.Background(), live[0])
+	assert.NoError(t, err)
+}
+
+func TestPurge_Revoked(t *testing.T) {
+	s := store.NewMemoryStore()
+	j := newJanitorHelper(t, s, 10)
+
+	jtis := insertTokensHelper(t, s, 2, time.Hour*48)
+	_, err := s.RevokeToken(context.Background(), jtis[0])
+	require.NoError(t, err)
+
+	err = j.Purge(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), j.Stats().RevokedTokens)
+
+	_, err = s.GetTokenByJTI(context.Background(), jtis[0])
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	ok, err := s.IsRevoked(context.Background(), jtis[0])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = s.GetTokenByJTI(context.Background(), jtis[1])
+	assert.NoError(t, err)
+}
+
+func TestPurge_ExpiredRevocations(t *testing.T) {
+	s := store.NewMemoryStore()
+	j := newJanitorHelper(t, s, 10)
+
+	err := s.CreateRevocation(context.Background(), sqlc.CreateRevocationParams{
+		Jti:       "access",
+		Kind:      pb.TokenKind_TOKEN_KIND_ACCESS.String(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	err = j.Purge(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), j.Stats().ExpiredRevocations)
+}
+
+func TestPurge_Logged(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	s := store.NewMemoryStore()
+	j := newJanitorHelper(t, s, 2)
+	_ = insertTokensHelper(t, s, 3, time.Hour)
+
+	require.NoError(t, j.Purge(context.Background()))
+	assert.Contains(t, buf.String(), "janitor: purged 3 expired tokens, 0 revoked tokens and 0 expired revocations")
+
+	buf.Reset()
+	require.NoError(t, j.Purge(context.Background()))
+	assert.Contains(t, buf.String(), "janitor: purged 0 expired tokens, 0 revoked tokens and 0 expired revocations")
+	assert.Equal(t, uint64(3), j.Stats().ExpiredTokens)
+}
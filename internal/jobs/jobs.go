@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidJob   = errors.New("invalid job")
+	ErrDuplicateJob = errors.New("duplicate job")
+	ErrNotFound     = errors.New("no run recorded")
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Run records a single execution of a job, identified by the slot of its
+// schedule it was started for.
+type Run struct {
+	Job        string    `json:"job"`
+	Slot       time.Time `json:"slot"`
+	Owner      string    `json:"owner"`
+	Status     Status    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// Schedule returns the slot a job runs in next. Every instance must compute
+// the same slots, so that they compete for the same runs.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+type every time.Duration
+
+// Every schedules a job at every multiple of d since the Unix epoch. Register
+// rejects a d which is not positive.
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+func (e every) Next(after time.Time) time.Time {
+	d := time.Duration(e)
+	return after.Truncate(d).Add(d)
+}
+
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Timeout bounds a single run, zero leaves it unbounded.
+	Timeout time.Duration
+	// Run is cancelled when the Scheduler shuts down and should return
+	// promptly.
+	Run func(ctx context.Context) error
+}
+
+// Lease makes sure that exactly one instance executes each run of a job and
+// keeps the last run of every job.
+type Lease interface {
+	// Acquire claims run for its owner and reports whether it did, ttl is how
+	// long the claim has to outlive the run.
+	Acquire(ctx context.Context, run Run, ttl time.Duration) (bool, error)
+	// Finish records the outcome of a run previously acquired.
+	Finish(ctx context.Context, run Run) error
+	// Last returns the last run of job, or ErrNotFound.
+	Last(ctx context.Context, job string) (*Run, error)
+}
+
+var (
+	_ Lease = (*cacheLease)(nil)
+	_ Lease = (*storeLease)(nil)
+)
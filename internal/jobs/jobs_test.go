@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvery_Next(t *testing.T) {
+	s := Every(time.Minute)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, base.Add(time.Minute), s.Next(base))
+	assert.Equal(t, base.Add(time.Minute), s.Next(base.Add(time.Second*59)))
+	assert.Equal(t, base.Add(time.Minute*2), s.Next(base.Add(time.Minute)))
+}
+
+func TestEvery_Aligned(t *testing.T) {
+	s := Every(time.Hour)
+
+	// Instances started at different times agree on the slot.
+	a := s.Next(time.Date(2024, 1, 1, 12, 10, 0, 0, time.UTC))
+	b := s.Next(time.Date(2024, 1, 1, 12, 50, 0, 0, time.UTC))
+	assert.Equal(t, a, b)
+}
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gebhn/auth-service/internal/cache"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// cacheRunRetention is how long the last run of a job is kept in the cache.
+const cacheRunRetention = time.Hour * 24 * 7
+
+// cacheLease claims a run with SetNX on a key per slot, which expires once
+// the slot has passed.
+type cacheLease struct {
+	c cache.Cache
+}
+
+func NewCacheLease(c cache.Cache) *cacheLease {
+	return &cacheLease{c: c}
+}
+
+func (l *cacheLease) Acquire(ctx context.Context, run Run, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("jobs:%s:%s", run.Job, strconv.FormatInt(run.Slot.Unix(), 10))
+	ok, err := l.c.SetNX(ctx, key, run.Owner, ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, l.Finish(ctx, run)
+}
+
+func (l *cacheLease) Finish(ctx context.Context, run Run) error {
+	b, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	_, err = l.c.Set(ctx, "jobs:"+run.Job+":last", string(b), cacheRunRetention)
+	return err
+}
+
+func (l *cacheLease) Last(ctx context.Context, job string) (*Run, error) {
+	v, err := l.c.Get(ctx, "jobs:"+job+":last")
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{}
+	if err := json.Unmarshal([]byte(v), run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// storeLease keeps a row per job which only moves forward to a later slot,
+// so the row doubles as the record of the last run.
+type storeLease struct {
+	s store.Store
+}
+
+func NewStoreLease(s store.Store) *storeLease {
+	return &storeLease{s: s}
+}
+
+func (l *storeLease) Acquire(ctx context.Context, run Run, ttl time.Duration) (bool, error) {
+	n, err := l.s.AcquireJobRun(ctx, sqlc.AcquireJobRunParams{
+		Name:  run.Job,
+		Slot:  run.Slot.UTC(),
+		Owner: run.Owner,
+	})
+	return n == 1, err
+}
+
+func (l *storeLease) Finish(ctx context.Context, run Run) error {
+	var msg *string
+	if run.Error != "" {
+		msg = &run.Error
+	}
+	_, err := l.s.FinishJobRun(ctx, sqlc.FinishJobRunParams{
+		Status: string(run.Status),
+		Error:  msg,
+		Name:   run.Job,
+		Slot:   run.Slot.UTC(),
+		Owner:  run.Owner,
+	})
+	return err
+}
+
+func (l *storeLease) Last(ctx context.Context, job string) (*Run, error) {
+	r, err := l.s.GetJobRun(ctx, job)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{
+		Job:       r.Name,
+		Slot:      r.Slot,
+		Owner:     r.Owner,
+		Status:    Status(r.Status),
+		StartedAt: r.StartedAt,
+	}
+	if r.Error != nil {
+		run.Error = *r.Error
+	}
+	if r.FinishedAt != nil {
+		run.FinishedAt = *r.FinishedAt
+	}
+	return run, nil
+}
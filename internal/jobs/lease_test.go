@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/internal/cache"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+var testRedis *miniredis.Miniredis
+
+var testCache cache.Cache
+
+func TestMain(m *testing.M) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+
+	testRedis = mr
+	testCache = cache.NewRedisCache(mr.Addr(), "")
+	defer testCache.Close()
+
+	os.Exit(m.Run())
+}
+
+// forEachLease runs fn against a fresh Lease of every kind.
+func forEachLease(t *testing.T, fn func(t *testing.T, l Lease)) {
+	t.Helper()
+
+	t.Run("cache", func(t *testing.T) {
+		t.Cleanup(testRedis.FlushAll)
+		fn(t, NewCacheLease(testCache))
+	})
+	t.Run("store", func(t *testing.T) {
+		fn(t, NewStoreLease(store.NewMemoryStore()))
+	})
+}
+
+func runHelper(owner string, slot time.Time) Run {
+	return Run{
+		Job:       "job",
+		Slot:      slot,
+		Owner:     owner,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+	}
+}
+
+func TestLease_Acquire(t *testing.T) {
+	forEachLease(t, func(t *testing.T, l Lease) {
+		slot := time.Now().Truncate(time.Minute)
+
+		ok, err := l.Acquire(context.Background(), runHelper("a", slot), time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = l.Acquire(context.Background(), runHelper("b", slot), time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = l.Acquire(context.Background(), runHelper("b", slot.Add(time.Minute)), time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestLease_Last(t *testing.T) {
+	forEachLease(t, func(t *testing.T, l Lease) {
+		_, err := l.Last(context.Background(), "job")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		run := runHelper("a", time.Now().Truncate(time.Minute))
+		ok, err := l.Acquire(context.Background(), run, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		last, err := l.Last(context.Background(), "job")
+		assert.NoError(t, err)
+		assert.Equal(t, StatusRunning, last.Status)
+		assert.Equal(t, "a", last.Owner)
+
+		run.Status = StatusFailed
+		run.Error = "boom"
+		run.FinishedAt = time.Now()
+		err = l.Finish(context.Background(), run)
+		assert.NoError(t, err)
+
+		last, err = l.Last(context.Background(), "job")
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, last.Status)
+		assert.Equal(t, "boom", last.Error)
+		assert.True(t, run.Slot.Equal(last.Slot))
+		assert.False(t, last.FinishedAt.IsZero())
+	})
+}
+
+func TestCacheLease_Expires(t *testing.T) {
+	t.Cleanup(testRedis.FlushAll)
+	l := NewCacheLease(testCache)
+	slot := time.Now().Truncate(time.Minute)
+
+	ok, err := l.Acquire(context.Background(), runHelper("a", slot), time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	testRedis.FastForward(time.Minute)
+
+	ok, err = l.Acquire(context.Background(), runHelper("b", slot), time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
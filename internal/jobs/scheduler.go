@@ -0,0 +1,173 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// finishTimeout bounds recording the outcome of a run, which happens even
+// when the run was cancelled by a shutdown.
+const finishTimeout = time.Second * 5
+
+// Stats reports how many runs of a job this instance executed, left to
+// another instance, or failed.
+type Stats struct {
+	Runs    uint64
+	Skipped uint64
+	Failed  uint64
+}
+
+type entry struct {
+	job Job
+
+	runs    atomic.Uint64
+	skipped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// Scheduler runs every registered job on its schedule, competing with the
+// other instances through a Lease so that each run happens exactly once.
+type Scheduler struct {
+	lease Lease
+	owner string
+	now   func() time.Time
+
+	mu   sync.Mutex
+	jobs map[string]*entry
+}
+
+// NewScheduler returns a Scheduler, owner identifies this instance in the
+// runs it executes.
+func NewScheduler(lease Lease, owner string) *Scheduler {
+	return &Scheduler{
+		lease: lease,
+		owner: owner,
+		now:   time.Now,
+		jobs:  map[string]*entry{},
+	}
+}
+
+// Register adds job to the Scheduler, it must be called before Run.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return ErrInvalidJob
+	}
+	// A schedule which does not move forward, such as Every(0), would run
+	// the job in a busy loop.
+	if now := time.Now(); !job.Schedule.Next(now).After(now) {
+		return ErrInvalidJob
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.Name]; ok {
+		return ErrDuplicateJob
+	}
+	s.jobs[job.Name] = &entry{job: job}
+	return nil
+}
+
+// Run schedules every job until ctx is cancelled, then waits for the runs in
+// progress to return.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	var wg sync.WaitGroup
+	for _, e := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	s.mu.Unlock()
+
+	wg.Wait()
+}
+
+// Last returns the last run of the named job on any instance.
+func (s *Scheduler) Last(ctx context.Context, name string) (*Run, error) {
+	return s.lease.Last(ctx, name)
+}
+
+func (s *Scheduler) Stats(name string) Stats {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return Stats{}
+	}
+	return Stats{
+		Runs:    e.runs.Load(),
+		Skipped: e.skipped.Load(),
+		Failed:  e.failed.Load(),
+	}
+}
+
+// loop waits for each slot of the job in turn. Slots which pass while a run
+// is still in progress are skipped rather than caught up on.
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		slot := e.job.Schedule.Next(s.now())
+		t := time.NewTimer(slot.Sub(s.now()))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		if err := s.run(ctx, e, slot); err != nil {
+			log.Printf("jobs: %s failed: %v", e.job.Name, err)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, e *entry, slot time.Time) error {
+	run := Run{
+		Job:       e.job.Name,
+		Slot:      slot,
+		Owner:     s.owner,
+		Status:    StatusRunning,
+		StartedAt: s.now(),
+	}
+	ok, err := s.lease.Acquire(ctx, run, e.job.Schedule.Next(slot).Sub(slot))
+	if err != nil {
+		e.failed.Add(1)
+		return err
+	}
+	if !ok {
+		e.skipped.Add(1)
+		return nil
+	}
+	e.runs.Add(1)
+
+	runCtx := ctx
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+	err = e.job.Run(runCtx)
+
+	run.Status = StatusSucceeded
+	run.FinishedAt = s.now()
+	if err != nil {
+		e.failed.Add(1)
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	if ferr := s.lease.Finish(finishCtx, run); ferr != nil {
+		log.Printf("jobs: failed to record run of %s: %v", e.job.Name, ferr)
+	}
+	return err
+}
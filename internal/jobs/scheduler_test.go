@@ -0,0 +1,138 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+func TestRegister_Invalid(t *testing.T) {
+	s := NewScheduler(NewStoreLease(store.NewMemoryStore()), "a")
+	noop := func(ctx context.Context) error { return nil }
+
+	assert.ErrorIs(t, s.Register(Job{Schedule: Every(time.Minute), Run: noop}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Register(Job{Name: "job", Run: noop}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Register(Job{Name: "job", Schedule: Every(time.Minute)}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Register(Job{Name: "job", Schedule: Every(0), Run: noop}), ErrInvalidJob)
+	assert.ErrorIs(t, s.Register(Job{Name: "job", Schedule: Every(-time.Minute), Run: noop}), ErrInvalidJob)
+
+	assert.NoError(t, s.Register(Job{Name: "job", Schedule: Every(time.Minute), Run: noop}))
+	assert.ErrorIs(t, s.Register(Job{Name: "job", Schedule: Every(time.Minute), Run: noop}), ErrDuplicateJob)
+}
+
+// leaseMock counts the successful acquisitions of every slot.
+type leaseMock struct {
+	Lease
+
+	mu    sync.Mutex
+	slots map[time.Time]int
+}
+
+func (l *leaseMock) Acquire(ctx context.Context, run Run, ttl time.Duration) (bool, error) {
+	ok, err := l.Lease.Acquire(ctx, run, ttl)
+	if ok {
+		l.mu.Lock()
+		l.slots[run.Slot]++
+		l.mu.Unlock()
+	}
+	return ok, err
+}
+
+func TestRun_ExactlyOnce(t *testing.T) {
+	lease := &leaseMock{Lease: NewStoreLease(store.NewMemoryStore()), slots: map[time.Time]int{}}
+	job := Job{
+		Name:     "job",
+		Schedule: Every(time.Millisecond * 20),
+		Run:      func(ctx context.Context) error { return nil },
+	}
+
+	a := NewScheduler(lease, "a")
+	b := NewScheduler(lease, "b")
+	require.NoError(t, a.Register(job))
+	require.NoError(t, b.Register(job))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, s := range []*Scheduler{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	runs := a.Stats("job").Runs + b.Stats("job").Runs
+	assert.Greater(t, runs, uint64(0))
+	assert.Equal(t, int(runs), len(lease.slots))
+	for slot, n := range lease.slots {
+		assert.Equal(t, 1, n, "slot %s ran more than once", slot)
+	}
+}
+
+func TestRun_Shutdown(t *testing.T) {
+	s := NewScheduler(NewStoreLease(store.NewMemoryStore()), "a")
+
+	started := make(chan struct{})
+	returned := make(chan struct{})
+	err := s.Register(Job{
+		Name:     "job",
+		Schedule: Every(time.Millisecond * 10),
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(returned)
+			return ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+
+	<-started
+	cancel()
+	<-done
+
+	select {
+	case <-returned:
+	default:
+		t.Fatal("Run returned before the job did")
+	}
+
+	last, err := s.Last(context.Background(), "job")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusFailed, last.Status)
+	assert.Equal(t, context.Canceled.Error(), last.Error)
+}
+
+func TestRun_Timeout(t *testing.T) {
+	s := NewScheduler(NewStoreLease(store.NewMemoryStore()), "a")
+	err := s.Register(Job{
+		Name:     "job",
+		Schedule: Every(time.Minute),
+		Timeout:  time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	require.NoError(t, err)
+
+	err = s.run(context.Background(), s.jobs["job"], time.Now().Truncate(time.Minute))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, uint64(1), s.Stats("job").Failed)
+}
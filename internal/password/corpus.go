@@ -0,0 +1,66 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// prefixLength is how many hex characters of the SHA-1 of a password name
+// its partition, as in the k-anonymity range API of Have I Been Pwned.
+const prefixLength = 5
+
+// Corpus reports how often a password appears in known breaches.
+type Corpus interface {
+	Count(ctx context.Context, password string) (int, error)
+}
+
+// fsCorpus reads a corpus of breached password hashes partitioned on disk:
+// one file per uppercase hex prefix of the SHA-1, holding a "SUFFIX:COUNT"
+// line per hash. Only the partition of the password is read, and a missing
+// partition holds no hash.
+type fsCorpus struct {
+	fsys fs.FS
+}
+
+func NewCorpus(fsys fs.FS) *fsCorpus {
+	return &fsCorpus{fsys: fsys}
+}
+
+func (c *fsCorpus) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+
+	f, err := c.fsys.Open(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		s, count, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(s, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package password
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// corpusHelper partitions counts the way the corpus is laid out on disk.
+func corpusHelper(t *testing.T, counts map[string]string) fstest.MapFS {
+	t.Helper()
+
+	fsys := fstest.MapFS{}
+	for password, count := range counts {
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+		f, ok := fsys[hash[:prefixLength]]
+		if !ok {
+			f = &fstest.MapFile{Data: []byte("0000000000000000000000000000000000A:1\r\n")}
+			fsys[hash[:prefixLength]] = f
+		}
+		f.Data = append(f.Data, hash[prefixLength:]+":"+count+"\r\n"...)
+	}
+	return fsys
+}
+
+func TestCorpusCount_Success(t *testing.T) {
+	c := NewCorpus(corpusHelper(t, map[string]string{"password": "9545824", "hunter2": "17"}))
+
+	n, err := c.Count(context.Background(), "password")
+	require.NoError(t, err)
+	assert.Equal(t, 9545824, n)
+
+	n, err = c.Count(context.Background(), "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, 17, n)
+}
+
+func TestCorpusCount_NotBreached(t *testing.T) {
+	c := NewCorpus(corpusHelper(t, map[string]string{"password": "1"}))
+
+	n, err := c.Count(context.Background(), "correct horse battery staple")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestCorpusCount_Malformed(t *testing.T) {
+	c := NewCorpus(corpusHelper(t, map[string]string{"password": "many"}))
+
+	_, err := c.Count(context.Background(), "password")
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package password
+
+import (
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+// Expiry decides at Login whether a user has to change their password before
+// being issued a session, in which case Login only issues a
+// TOKEN_KIND_PASSWORD_CHANGE token.
+type Expiry struct {
+	maxAge time.Duration
+}
+
+// NewExpiry returns an Expiry of passwords older than maxAge, passwords never
+// expire when it is zero.
+func NewExpiry(maxAge time.Duration) *Expiry {
+	return &Expiry{maxAge: maxAge}
+}
+
+// NewExpiryFromConfig returns an Expiry of passwords older than
+// MAX_PASSWORD_AGE.
+func NewExpiryFromConfig() *Expiry {
+	return NewExpiry(config.GetMaxPasswordAge())
+}
+
+// Required reports whether u has to change their password, and why.
+func (e *Expiry) Required(u *sqlc.User, now time.Time) (pb.PasswordChangeReason, bool) {
+	if u.MustChangePassword {
+		return pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_REQUIRED, true
+	}
+	if e.maxAge <= 0 {
+		return pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_UNKNOWN, false
+	}
+
+	// Users created before password_changed_at existed have never changed it.
+	changedAt := u.CreatedAt
+	if u.PasswordChangedAt != nil {
+		changedAt = *u.PasswordChangedAt
+	}
+	if now.Sub(changedAt) >= e.maxAge {
+		return pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_EXPIRED, true
+	}
+	return pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_UNKNOWN, false
+}
@@ -0,0 +1,62 @@
+package password
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func TestExpiry_Required(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour * 24 * 10)
+
+	u := &sqlc.User{CreatedAt: changedAt, PasswordChangedAt: &changedAt, MustChangePassword: true}
+	reason, ok := NewExpiry(0).Required(u, now)
+	assert.True(t, ok)
+	assert.Equal(t, pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_REQUIRED, reason)
+}
+
+func TestExpiry_Expired(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour * 24 * 10)
+	e := NewExpiry(time.Hour * 24 * 7)
+
+	reason, ok := e.Required(&sqlc.User{PasswordChangedAt: &changedAt}, now)
+	assert.True(t, ok)
+	assert.Equal(t, pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_EXPIRED, reason)
+
+	// Without password_changed_at the password dates from the user.
+	reason, ok = e.Required(&sqlc.User{CreatedAt: changedAt}, now)
+	assert.True(t, ok)
+	assert.Equal(t, pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_EXPIRED, reason)
+}
+
+func TestExpiry_NotRequired(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour * 24 * 10)
+
+	_, ok := NewExpiry(time.Hour*24*30).Required(&sqlc.User{PasswordChangedAt: &changedAt}, now)
+	assert.False(t, ok)
+
+	_, ok = NewExpiry(0).Required(&sqlc.User{CreatedAt: changedAt}, now)
+	assert.False(t, ok)
+}
+
+func TestNewExpiryFromConfig(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour * 24 * 10)
+	u := &sqlc.User{PasswordChangedAt: &changedAt}
+
+	t.Setenv("MAX_PASSWORD_AGE", "0")
+	_, ok := NewExpiryFromConfig().Required(u, now)
+	assert.False(t, ok)
+
+	t.Setenv("MAX_PASSWORD_AGE", "168h")
+	reason, ok := NewExpiryFromConfig().Required(u, now)
+	assert.True(t, ok)
+	assert.Equal(t, pb.PasswordChangeReason_PASSWORD_CHANGE_REASON_EXPIRED, reason)
+}
@@ -0,0 +1,86 @@
+package password
+
+import (
+	"context"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// History rejects the reuse of the last passwords of a user, at
+// ChangePassword and password reset.
+type History struct {
+	size int
+	// matches reports whether password hashes to hash.
+	matches func(hash, password string) bool
+}
+
+// NewHistory returns a History of the last size passwords of every user, a
+// size of zero disables it.
+func NewHistory(size int, matches func(hash, password string) bool) *History {
+	return &History{
+		size:    size,
+		matches: matches,
+	}
+}
+
+// NewHistoryFromConfig returns a History of the last PASSWORD_HISTORY_SIZE
+// passwords of every user.
+func NewHistoryFromConfig(matches func(hash, password string) bool) *History {
+	return NewHistory(config.GetPasswordHistorySize(), matches)
+}
+
+// Check returns an *Error when password is the current password of userID or
+// one of the previous ones kept.
+func (h *History) Check(ctx context.Context, s store.Store, userID string, password string) error {
+	if h.size <= 0 {
+		return nil
+	}
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	entries, err := s.GetPasswordHistory(ctx, sqlc.GetPasswordHistoryParams{
+		UserID: userID,
+		Size:   int64(h.size),
+	})
+	if err != nil {
+		return err
+	}
+
+	hashes := []string{u.PasswordHash}
+	for _, e := range entries {
+		hashes = append(hashes, e.PasswordHash)
+	}
+	for _, hash := range hashes {
+		if h.matches(hash, password) {
+			return &Error{Violations: []pb.PasswordViolation{pb.PasswordViolation_PASSWORD_VIOLATION_REUSED}}
+		}
+	}
+	return nil
+}
+
+// Record keeps hash, the new password of userID, and prunes whatever falls
+// out of the History. It belongs in the transaction which sets the password,
+// including at registration.
+func (h *History) Record(ctx context.Context, s store.Store, userID string, hash string) error {
+	if h.size <= 0 {
+		return nil
+	}
+
+	err := s.AddPasswordHistory(ctx, sqlc.AddPasswordHistoryParams{
+		UserID:       userID,
+		PasswordHash: hash,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.PrunePasswordHistory(ctx, sqlc.PrunePasswordHistoryParams{
+		UserID: userID,
+		Keep:   int64(h.size),
+	})
+	return err
+}
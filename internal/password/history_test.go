@@ -0,0 +1,108 @@
+package password
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+func matchesMock(hash, password string) bool {
+	return hash == "hash:"+password
+}
+
+// changePasswordHelper sets the password of user 1 the way ChangePassword
+// does.
+func changePasswordHelper(t *testing.T, s store.Store, h *History, password string) error {
+	t.Helper()
+
+	return s.ExecTx(context.Background(), func(s store.Store) error {
+		if err := h.Check(context.Background(), s, "1", password); err != nil {
+			return err
+		}
+		hash := "hash:" + password
+		_, err := s.UpdateUser(context.Background(), sqlc.UpdateUserParams{UserID: "1", PasswordHash: hash})
+		if err != nil {
+			return err
+		}
+		return h.Record(context.Background(), s, "1", hash)
+	})
+}
+
+func newHistoryHelper(t *testing.T, size int) (*History, store.Store) {
+	t.Helper()
+
+	s := store.NewMemoryStore()
+	err := s.CreateUser(context.Background(), sqlc.CreateUserParams{
+		UserID:       "1",
+		Username:     "username1",
+		Email:        "username1@mail.me",
+		PasswordHash: "hash:first",
+	})
+	require.NoError(t, err)
+
+	h := NewHistory(size, matchesMock)
+	require.NoError(t, h.Record(context.Background(), s, "1", "hash:first"))
+	return h, s
+}
+
+func TestHistory_Reused(t *testing.T) {
+	h, s := newHistoryHelper(t, 3)
+
+	for _, password := range []string{"second", "third"} {
+		require.NoError(t, changePasswordHelper(t, s, h, password))
+	}
+
+	for _, password := range []string{"first", "second", "third"} {
+		err := changePasswordHelper(t, s, h, password)
+		perr := &Error{}
+		require.ErrorAs(t, err, &perr, password)
+		assert.Equal(t, []pb.PasswordViolation{pb.PasswordViolation_PASSWORD_VIOLATION_REUSED}, perr.Violations)
+	}
+}
+
+func TestHistory_Pruned(t *testing.T) {
+	h, s := newHistoryHelper(t, 2)
+
+	for _, password := range []string{"second", "third"} {
+		require.NoError(t, changePasswordHelper(t, s, h, password))
+	}
+
+	entries, err := s.GetPasswordHistory(context.Background(), sqlc.GetPasswordHistoryParams{UserID: "1", Size: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	assert.NoError(t, changePasswordHelper(t, s, h, "first"))
+}
+
+func TestNewHistoryFromConfig(t *testing.T) {
+	t.Setenv("PASSWORD_HISTORY_SIZE", "2")
+	_, s := newHistoryHelper(t, 0)
+	h := NewHistoryFromConfig(matchesMock)
+
+	for _, password := range []string{"second", "third"} {
+		require.NoError(t, changePasswordHelper(t, s, h, password))
+	}
+
+	entries, err := s.GetPasswordHistory(context.Background(), sqlc.GetPasswordHistoryParams{UserID: "1", Size: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	assert.Error(t, changePasswordHelper(t, s, h, "second"))
+	assert.NoError(t, changePasswordHelper(t, s, h, "first"))
+}
+
+func TestHistory_Disabled(t *testing.T) {
+	h, s := newHistoryHelper(t, 0)
+
+	assert.NoError(t, changePasswordHelper(t, s, h, "second"))
+
+	entries, err := s.GetPasswordHistory(context.Background(), sqlc.GetPasswordHistoryParams{UserID: "1", Size: 10})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,154 @@
+package password
+
+import (
+	"context"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+var _ Corpus = (*fsCorpus)(nil)
+
+// Policy lists the rules a new password must follow. Zero values disable the
+// respective rule.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	// MinClasses is how many of lowercase, uppercase, digits and symbols a
+	// password must mix.
+	MinClasses int
+	// DisallowIdentity rejects passwords containing the username or email.
+	DisallowIdentity bool
+	// MinScore is the least Score a password must reach, from 0 to 4.
+	MinScore int
+}
+
+// Identity is the account a password is chosen for.
+type Identity struct {
+	Username string
+	Email    string
+}
+
+// Error lists every rule a password breaks.
+type Error struct {
+	Violations []pb.PasswordViolation
+}
+
+func (e *Error) Error() string {
+	names := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		names[i] = v.String()
+	}
+	return "password rejected: " + strings.Join(names, ", ")
+}
+
+// Checker enforces a Policy at Register, ChangePassword and password reset.
+type Checker struct {
+	policy Policy
+	corpus Corpus
+}
+
+// NewChecker returns a Checker, corpus may be nil to skip the breached
+// password check.
+func NewChecker(policy Policy, corpus Corpus) *Checker {
+	return &Checker{
+		policy: policy,
+		corpus: corpus,
+	}
+}
+
+// NewCheckerFromConfig returns a Checker enforcing the configured Policy, and
+// the breached password corpus of BREACHED_CORPUS_DIR when it is set.
+func NewCheckerFromConfig() *Checker {
+	policy := Policy{
+		MinLength:        config.GetPasswordMinLength(),
+		MaxLength:        config.GetPasswordMaxLength(),
+		MinClasses:       config.GetPasswordMinClasses(),
+		DisallowIdentity: config.GetPasswordDisallowIdentity(),
+		MinScore:         config.GetPasswordMinScore(),
+	}
+	var corpus Corpus
+	if dir := config.GetBreachedCorpusDir(); dir != "" {
+		corpus = NewCorpus(os.DirFS(dir))
+	}
+	return NewChecker(policy, corpus)
+}
+
+// Check returns an *Error when password breaks the policy, or any error of
+// the corpus.
+func (c *Checker) Check(ctx context.Context, password string, id Identity) error {
+	violations := []pb.PasswordViolation{}
+	n := utf8.RuneCountInString(password)
+
+	if n < c.policy.MinLength {
+		violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_TOO_SHORT)
+	}
+	if c.policy.MaxLength > 0 && n > c.policy.MaxLength {
+		violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_TOO_LONG)
+	}
+	if classes(password) < c.policy.MinClasses {
+		violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_MISSING_CHARACTER_CLASSES)
+	}
+	if c.policy.DisallowIdentity {
+		violations = append(violations, identity(password, id)...)
+	}
+	if Score(password) < c.policy.MinScore {
+		violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_TOO_WEAK)
+	}
+
+	if c.corpus != nil {
+		count, err := c.corpus.Count(ctx, password)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_BREACHED)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+// classes counts the character classes password mixes.
+func classes(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// minIdentityLength keeps short usernames from rejecting most passwords.
+const minIdentityLength = 3
+
+func identity(password string, id Identity) []pb.PasswordViolation {
+	violations := []pb.PasswordViolation{}
+	password = strings.ToLower(password)
+
+	if u := strings.ToLower(id.Username); len(u) >= minIdentityLength && strings.Contains(password, u) {
+		violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_CONTAINS_USERNAME)
+	}
+
+	email := strings.ToLower(id.Email)
+	local, _, _ := strings.Cut(email, "@")
+	if len(local) >= minIdentityLength && strings.Contains(password, local) {
+		violations = append(violations, pb.PasswordViolation_PASSWORD_VIOLATION_CONTAINS_EMAIL)
+	}
+	return violations
+}
@@ -0,0 +1,117 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+var testPolicy = Policy{
+	MinLength:        10,
+	MaxLength:        64,
+	MinClasses:       2,
+	DisallowIdentity: true,
+	MinScore:         3,
+}
+
+var testIdentity = Identity{Username: "gopher", Email: "gladys@mail.me"}
+
+type corpusMock struct {
+	err error
+}
+
+func (c corpusMock) Count(ctx context.Context, password string) (int, error) {
+	return 0, c.err
+}
+
+func violationsHelper(t *testing.T, c *Checker, password string) []pb.PasswordViolation {
+	t.Helper()
+
+	err := c.Check(context.Background(), password, testIdentity)
+	require.Error(t, err)
+
+	perr := &Error{}
+	require.ErrorAs(t, err, &perr)
+	return perr.Violations
+}
+
+func TestCheck_Success(t *testing.T) {
+	c := NewChecker(testPolicy, NewCorpus(corpusHelper(t, map[string]string{"password": "1"})))
+
+	err := c.Check(context.Background(), "Tr0ub4dor&3 staple", testIdentity)
+	assert.NoError(t, err)
+}
+
+func TestCheck_Violations(t *testing.T) {
+	c := NewChecker(testPolicy, nil)
+
+	assert.Equal(t, []pb.PasswordViolation{
+		pb.PasswordViolation_PASSWORD_VIOLATION_TOO_SHORT,
+		pb.PasswordViolation_PASSWORD_VIOLATION_MISSING_CHARACTER_CLASSES,
+		pb.PasswordViolation_PASSWORD_VIOLATION_TOO_WEAK,
+	}, violationsHelper(t, c, "abcdef"))
+
+	assert.Equal(t, []pb.PasswordViolation{
+		pb.PasswordViolation_PASSWORD_VIOLATION_CONTAINS_USERNAME,
+		pb.PasswordViolation_PASSWORD_VIOLATION_CONTAINS_EMAIL,
+	}, violationsHelper(t, c, "GOPHER-gladys-1987x"))
+
+	long := make([]byte, 65)
+	for i := range long {
+		long[i] = byte('a' + i%26)
+	}
+	assert.Contains(t, violationsHelper(t, c, string(long)+"1"), pb.PasswordViolation_PASSWORD_VIOLATION_TOO_LONG)
+}
+
+func TestCheck_Breached(t *testing.T) {
+	c := NewChecker(Policy{}, NewCorpus(corpusHelper(t, map[string]string{"Tr0ub4dor&3": "42"})))
+
+	assert.Equal(t, []pb.PasswordViolation{
+		pb.PasswordViolation_PASSWORD_VIOLATION_BREACHED,
+	}, violationsHelper(t, c, "Tr0ub4dor&3"))
+}
+
+func TestCheck_CorpusError(t *testing.T) {
+	c := NewChecker(Policy{}, corpusMock{err: errors.New("disk on fire")})
+
+	err := c.Check(context.Background(), "Tr0ub4dor&3", testIdentity)
+	assert.Error(t, err)
+	assert.NotErrorAs(t, err, new(*Error))
+}
+
+func TestNewCheckerFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	for name, f := range corpusHelper(t, map[string]string{"Tr0ub4dor&3": "42"}) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), f.Data, 0o600))
+	}
+	t.Setenv("PASSWORD_MIN_LENGTH", "4")
+	t.Setenv("PASSWORD_MAX_LENGTH", "256")
+	t.Setenv("PASSWORD_MIN_CLASSES", "0")
+	t.Setenv("PASSWORD_MIN_SCORE", "0")
+	t.Setenv("PASSWORD_DISALLOW_IDENTITY", "false")
+	t.Setenv("BREACHED_CORPUS_DIR", dir)
+
+	c := NewCheckerFromConfig()
+
+	err := c.Check(context.Background(), "gopher", testIdentity)
+	assert.NoError(t, err)
+
+	err = c.Check(context.Background(), strings.Repeat("a", 200), testIdentity)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []pb.PasswordViolation{
+		pb.PasswordViolation_PASSWORD_VIOLATION_TOO_SHORT,
+	}, violationsHelper(t, c, "abc"))
+
+	assert.Equal(t, []pb.PasswordViolation{
+		pb.PasswordViolation_PASSWORD_VIOLATION_BREACHED,
+	}, violationsHelper(t, c, "Tr0ub4dor&3"))
+}
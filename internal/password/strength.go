@@ -0,0 +1,180 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Score estimates how hard password is to guess, in the spirit of zxcvbn, from
+// 0 for less than a thousand guesses to 4 for more than ten billion. The
+// password is split into the longest patterns an attacker would try first,
+// common words, repeats, sequences and keyboard runs, and brute force for
+// anything else.
+func Score(password string) int {
+	switch g := guessesLog10(password); {
+	case g < 3:
+		return 0
+	case g < 6:
+		return 1
+	case g < 8:
+		return 2
+	case g < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+const (
+	// dictionaryGuesses is the cost of a common word, including its case and
+	// substitution variants.
+	dictionaryGuesses = 100
+	// runGuesses is the cost of each starting point and direction of a
+	// sequence or keyboard run.
+	runGuesses = 50
+	minPattern = 3
+)
+
+// dictionary holds base words which lead the breached password lists, the
+// corpus catches the rest.
+var dictionary = []string{
+	"password", "passwort", "qwerty", "letmein", "welcome", "admin", "login",
+	"iloveyou", "monkey", "dragon", "football", "baseball", "soccer", "master",
+	"hello", "sunshine", "shadow", "princess", "trustno", "secret", "freedom",
+	"whatever", "superman", "batman", "michael", "jordan", "charlie", "summer",
+	"winter", "spring", "autumn", "flower", "starwars", "pokemon", "computer",
+	"internet", "changeme", "default", "access", "mustang", "killer", "cheese",
+}
+
+var keyboard = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+func guessesLog10(password string) float64 {
+	lower := []rune(strings.ToLower(password))
+	plain := []rune(leet.Replace(string(lower)))
+	runes := []rune(password)
+
+	total := 0.0
+	// brute is where the pending run of characters matching no pattern
+	// starts, it is guessed character by character.
+	brute := 0
+	for i := 0; i < len(lower); {
+		n, g := longest(plain[i:], lower[i:])
+		if n == 0 {
+			i++
+			continue
+		}
+		total += bruteForce(runes[brute:i]) + g
+		i += n
+		brute = i
+	}
+	return total + bruteForce(runes[brute:])
+}
+
+// longest returns the length of the longest pattern at the start of the
+// password and the log10 of its guesses, or zero when none matches.
+func longest(plain, lower []rune) (int, float64) {
+	n, g := 0, 0.0
+	if w := word(plain); w > 0 {
+		n, g = w, math.Log10(dictionaryGuesses)
+	}
+	if r := repeat(lower); r >= minPattern && r > n {
+		n, g = r, math.Log10(charset(string(lower[:1]))*float64(r))
+	}
+	if r := max(sequence(lower), run(lower)); r >= minPattern && r > n {
+		n, g = r, math.Log10(runGuesses*float64(r))
+	}
+	return n, g
+}
+
+func bruteForce(rs []rune) float64 {
+	if len(rs) == 0 {
+		return 0
+	}
+	return float64(len(rs)) * math.Log10(charset(string(rs)))
+}
+
+// charset returns the size of the alphabet the classes of password span.
+func charset(password string) float64 {
+	var lower, upper, digit, symbol float64
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 26
+		case unicode.IsUpper(r):
+			upper = 26
+		case unicode.IsDigit(r):
+			digit = 10
+		default:
+			symbol = 33
+		}
+	}
+	return max(lower+upper+digit+symbol, 1)
+}
+
+// word returns the length of the longest dictionary word rs starts with.
+func word(rs []rune) int {
+	n := 0
+	s := string(rs)
+	for _, w := range dictionary {
+		if len(w) > n && strings.HasPrefix(s, w) {
+			n = len(w)
+		}
+	}
+	return n
+}
+
+func repeat(rs []rune) int {
+	n := 1
+	for n < len(rs) && rs[n] == rs[0] {
+		n++
+	}
+	return n
+}
+
+// sequence returns the length of the run of consecutive letters or digits,
+// either way, rs starts with.
+func sequence(rs []rune) int {
+	if len(rs) < 2 {
+		return len(rs)
+	}
+	step := rs[1] - rs[0]
+	if step != 1 && step != -1 {
+		return 1
+	}
+	n := 2
+	for n < len(rs) && rs[n]-rs[n-1] == step {
+		n++
+	}
+	return n
+}
+
+// run returns the length of the run along a keyboard row, either way, rs
+// starts with.
+func run(rs []rune) int {
+	best := 0
+	for _, row := range keyboard {
+		for _, r := range []string{row, reverse(row)} {
+			i := strings.IndexRune(r, rs[0])
+			if i < 0 {
+				continue
+			}
+			n := 0
+			for n < len(rs) && i+n < len(r) && rune(r[i+n]) == rs[n] {
+				n++
+			}
+			best = max(best, n)
+		}
+	}
+	return best
+}
+
+func reverse(s string) string {
+	rs := []rune(s)
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		rs[i], rs[j] = rs[j], rs[i]
+	}
+	return string(rs)
+}
@@ -0,0 +1,35 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	for password, score := range map[string]int{
+		"":                          0,
+		"password":                  0,
+		"P@ssw0rd":                  0,
+		"aaaaaaaaaaaa":              0,
+		"abcdefgh":                  0,
+		"qwertyuiop":                0,
+		"password2024":              2,
+		"zxcvbnm123456":             1,
+		"monkey-dragon":             1,
+		"Tr0ub4dor&3":               4,
+		"correcthorsebatterystaple": 4,
+	} {
+		assert.Equal(t, score, Score(password), password)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	assert.Equal(t, 8, word([]rune("password123")))
+	assert.Equal(t, 0, word([]rune("xpassword")))
+	assert.Equal(t, 4, repeat([]rune("aaaab")))
+	assert.Equal(t, 4, sequence([]rune("dcbax")))
+	assert.Equal(t, 1, sequence([]rune("acegi")))
+	assert.Equal(t, 5, run([]rune("sdfghx")))
+	assert.Equal(t, 3, run([]rune("poi")))
+}
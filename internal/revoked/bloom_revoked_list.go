@@ -0,0 +1,235 @@
+package revoked
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/bloom"
+)
+
+// BloomStats reports how lookups against a bloomRevokedList were answered.
+type BloomStats struct {
+	Lookups        uint64
+	Negatives      uint64
+	Positives      uint64
+	FalsePositives uint64
+}
+
+// FalsePositiveRate returns the share of jtis which were not revoked but
+// still had to be checked against the underlying List.
+func (s BloomStats) FalsePositiveRate() float64 {
+	if s.Negatives+s.FalsePositives == 0 {
+		return 0
+	}
+	return float64(s.FalsePositives) / float64(s.Negatives+s.FalsePositives)
+}
+
+// bloomRevokedList answers definite negatives from an in-memory bloom filter
+// and only consults the underlying List when a jti may have been revoked.
+type bloomRevokedList struct {
+	next     List
+	src      Enumerator
+	capacity uint
+	fpRate   float64
+
+	filter atomic.Pointer[bloom.Filter]
+
+	// rebuild serializes Rebuild, which owns pending while it runs.
+	rebuild sync.Mutex
+
+	mu         sync.Mutex
+	rebuilding bool
+	// pending are the jtis added or written while rebuilding, and inflight
+	// counts the writes to next which are not acknowledged yet. Both are
+	// merged into a rebuilt filter, since the Enumerator may have been read
+	// before they landed.
+	pending  []string
+	inflight map[string]int
+
+	lookups        atomic.Uint64
+	negatives      atomic.Uint64
+	positives      atomic.Uint64
+	falsePositives atomic.Uint64
+}
+
+func NewBloomRevokedList(ctx context.Context, next List, src Enumerator, capacity uint, fpRate float64) (*bloomRevokedList, error) {
+	b := &bloomRevokedList{
+		next:     next,
+		src:      src,
+		capacity: capacity,
+		fpRate:   fpRate,
+		inflight: map[string]int{},
+	}
+	if err := b.Rebuild(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *bloomRevokedList) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
+	if jti == "" {
+		return ErrInvalidKey
+	}
+	// The jti is added before it is written so that a concurrent Find can
+	// never observe a revocation the filter does not know about.
+	b.begin(jti)
+	defer b.done(jti)
+	return b.next.Create(ctx, jti, kind, exp)
+}
+
+func (b *bloomRevokedList) RevokeMany(ctx context.Context, tokens []Token) error {
+	for _, t := range tokens {
+		if t.Jti == "" {
+			return ErrInvalidKey
+		}
+	}
+	jtis := make([]string, len(tokens))
+	for i, t := range tokens {
+		jtis[i] = t.Jti
+	}
+	b.begin(jtis...)
+	defer b.done(jtis...)
+	return b.next.RevokeMany(ctx, tokens)
+}
+
+func (b *bloomRevokedList) Find(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, ErrInvalidKey
+	}
+	b.lookups.Add(1)
+	if !b.filter.Load().Test(jti) {
+		b.negatives.Add(1)
+		return false, nil
+	}
+	b.positives.Add(1)
+
+	ok, err := b.next.Find(ctx, jti)
+	if err == nil && !ok {
+		b.falsePositives.Add(1)
+	}
+	return ok, err
+}
+
+// Rebuild replaces the filter with one populated from the Enumerator, which
+// drops expired jtis and picks up revocations made by other instances.
+func (b *bloomRevokedList) Rebuild(ctx context.Context) error {
+	b.rebuild.Lock()
+	defer b.rebuild.Unlock()
+
+	b.mu.Lock()
+	b.rebuilding = true
+	b.pending = nil
+	b.mu.Unlock()
+
+	jtis, err := b.src.All(ctx)
+	if err != nil {
+		b.mu.Lock()
+		b.rebuilding = false
+		b.pending = nil
+		b.mu.Unlock()
+		return err
+	}
+
+	f := bloom.New(max(b.capacity, uint(len(jtis))), b.fpRate)
+	for _, jti := range jtis {
+		f.Add(jti)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, jti := range b.pending {
+		f.Add(jti)
+	}
+	for jti := range b.inflight {
+		f.Add(jti)
+	}
+	b.filter.Store(f)
+	b.rebuilding = false
+	b.pending = nil
+
+	return nil
+}
+
+// Run rebuilds the filter every interval until ctx is cancelled.
+func (b *bloomRevokedList) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := b.Rebuild(ctx); err != nil {
+				log.Printf("revoked: failed to rebuild bloom filter: %v", err)
+			}
+		}
+	}
+}
+
+func (b *bloomRevokedList) Observe(ctx context.Context, e Event) {
+	if e.Jti == "" {
+		return
+	}
+	b.add(e.Jti)
+}
+
+func (b *bloomRevokedList) Resync(ctx context.Context) error {
+	return b.Rebuild(ctx)
+}
+
+func (b *bloomRevokedList) Stats() BloomStats {
+	return BloomStats{
+		Lookups:        b.lookups.Load(),
+		Negatives:      b.negatives.Load(),
+		Positives:      b.positives.Load(),
+		FalsePositives: b.falsePositives.Load(),
+	}
+}
+
+func (b *bloomRevokedList) add(jti string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.filter.Load().Add(jti)
+	if b.rebuilding {
+		b.pending = append(b.pending, jti)
+	}
+}
+
+// begin adds jtis which are about to be written to next, they are kept
+// until done so that a Rebuild racing the write cannot drop them.
+func (b *bloomRevokedList) begin(jtis ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f := b.filter.Load()
+	for _, jti := range jtis {
+		f.Add(jti)
+		b.inflight[jti]++
+		if b.rebuilding {
+			b.pending = append(b.pending, jti)
+		}
+	}
+}
+
+// done records that the writes of jtis returned. A write which lands while
+// rebuilding may have been missed by the Enumerator, so it stays pending.
+func (b *bloomRevokedList) done(jtis ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, jti := range jtis {
+		if b.inflight[jti]--; b.inflight[jti] <= 0 {
+			delete(b.inflight, jti)
+		}
+		if b.rebuilding {
+			b.pending = append(b.pending, jti)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package revoked
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+// slowListMock is a List whose Create and All, when their channel is set,
+// hand a release channel to the test and block until it is closed. Create
+// lands its write once released, All scans before it blocks.
+type slowListMock struct {
+	mu   sync.Mutex
+	jtis map[string]bool
+
+	create chan chan struct{}
+	all    chan chan struct{}
+}
+
+func (l *slowListMock) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
+	hold(l.create)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.jtis[jti] = true
+	return nil
+}
+
+func (l *slowListMock) Find(ctx context.Context, jti string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.jtis[jti], nil
+}
+
+func (l *slowListMock) RevokeMany(ctx context.Context, tokens []Token) error {
+	for _, t := range tokens {
+		if err := l.Create(ctx, t.Jti, t.Kind, time.Until(t.ExpiresAt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *slowListMock) All(ctx context.Context) ([]string, error) {
+	l.mu.Lock()
+	jtis := slices.Collect(maps.Keys(l.jtis))
+	l.mu.Unlock()
+
+	hold(l.all)
+	return jtis, nil
+}
+
+func hold(c chan chan struct{}) {
+	if c == nil {
+		return
+	}
+	release := make(chan struct{})
+	c <- release
+	<-release
+}
+
+// newSlowBloomHelper returns a bloomRevokedList over a slowListMock which
+// blocks from now on.
+func newSlowBloomHelper(t *testing.T) (*bloomRevokedList, *slowListMock) {
+	t.Helper()
+
+	l := &slowListMock{jtis: map[string]bool{}}
+	b, err := NewBloomRevokedList(globalContext, l, l, 1000, 0.01)
+	require.NoError(t, err)
+
+	l.create = make(chan chan struct{})
+	l.all = make(chan chan struct{})
+	return b, l
+}
+
+func newBloomHelper(t *testing.T) *bloomRevokedList {
+	t.Helper()
+
+	b, err := NewBloomRevokedList(globalContext, testList.c, testList.c, 1000, 0.01)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestBloomCreate_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	err := b.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	ok, err := b.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBloomCreate_Invalid(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	err := b.Create(globalContext, "", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestBloomFind_Negative(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	testList.m.SetError("should not be called")
+	defer testList.m.SetError("")
+
+	ok, err := b.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), b.Stats().Negatives)
+}
+
+func TestBloomFind_Invalid(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	ok, err := b.Find(globalContext, "")
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestBloomFind_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	err := b.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	testList.m.SetError("failed to find")
+	defer testList.m.SetError("")
+
+	ok, err := b.Find(globalContext, "testJti")
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), "failed to find")
+}
+
+func TestBloomRebuild_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	err := testList.c.Create(globalContext, "existingJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	b := newBloomHelper(t)
+
+	ok, err := b.Find(globalContext, "existingJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	err = testList.c.Create(globalContext, "remoteJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	err = b.Rebuild(globalContext)
+	assert.NoError(t, err)
+
+	ok, err = b.Find(globalContext, "remoteJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBloomRebuild_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	testList.m.SetError("failed to scan")
+	defer testList.m.SetError("")
+
+	err := b.Rebuild(globalContext)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to scan")
+
+	_, err = NewBloomRevokedList(globalContext, testList.c, testList.c, 1000, 0.01)
+	assert.Error(t, err)
+}
+
+func TestBloomObserve_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	bl := newBloomHelper(t)
+
+	err := testList.c.Create(globalContext, "remoteJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	bl.Observe(globalContext, Event{Jti: "remoteJti"})
+
+	ok, err := bl.Find(globalContext, "remoteJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBloomStats_FalsePositiveRate(t *testing.T) {
+	s := BloomStats{Negatives: 99, FalsePositives: 1}
+	assert.InDelta(t, 0.01, s.FalsePositiveRate(), 0.0001)
+
+	s = BloomStats{}
+	assert.Zero(t, s.FalsePositiveRate())
+}
+
+func TestBloomRevokeMany_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	b := newBloomHelper(t)
+
+	err := b.RevokeMany(globalContext, []Token{
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	assert.NoError(t, err)
+
+	ok, err := b.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBloomRebuild_WriteLandsDuringScan(t *testing.T) {
+	b, l := newSlowBloomHelper(t)
+
+	created := make(chan error)
+	go func() {
+		created <- b.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, time.Minute)
+	}()
+	releaseCreate := <-l.create
+
+	rebuilt := make(chan error)
+	go func() {
+		rebuilt <- b.Rebuild(globalContext)
+	}()
+	// The Enumerator has scanned before the write landed.
+	releaseAll := <-l.all
+
+	close(releaseCreate)
+	require.NoError(t, <-created)
+	close(releaseAll)
+	require.NoError(t, <-rebuilt)
+
+	ok, err := b.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBloomRebuild_WriteInFlight(t *testing.T) {
+	b, l := newSlowBloomHelper(t)
+
+	created := make(chan error)
+	go func() {
+		created <- b.RevokeMany(globalContext, []Token{
+			{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+		})
+	}()
+	releaseCreate := <-l.create
+
+	rebuilt := make(chan error)
+	go func() {
+		rebuilt <- b.Rebuild(globalContext)
+	}()
+	close(<-l.all)
+	require.NoError(t, <-rebuilt)
+
+	// The write has not landed yet, the rebuilt filter must still hold it.
+	assert.True(t, b.filter.Load().Test("testJti"))
+
+	close(releaseCreate)
+	require.NoError(t, <-created)
+
+	ok, err := b.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,148 @@
+package revoked
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/broker"
+)
+
+// DefaultChannel is the channel revocation events are published on. Other
+// instances and downstream verifiers subscribe to it to learn about
+// revocations as they happen.
+const DefaultChannel = "auth-service:revoked"
+
+// Event is the JSON payload published for each revocation. A token
+// revocation sets Jti, while a user cutoff sets UserID instead.
+type Event struct {
+	Jti       string    `json:"jti,omitempty"`
+	Kind      string    `json:"kind,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	UserID    string    `json:"user_id,omitempty"`
+	NotBefore time.Time `json:"not_before,omitzero"`
+}
+
+// broadcastRevokedList publishes every revocation recorded through the
+// underlying List so that other instances can update their local state.
+type broadcastRevokedList struct {
+	next    List
+	b       broker.Broker
+	channel string
+}
+
+func NewBroadcastRevokedList(next List, b broker.Broker, channel string) *broadcastRevokedList {
+	return &broadcastRevokedList{
+		next:    next,
+		b:       b,
+		channel: channel,
+	}
+}
+
+// Create records the revocation and then publishes it. An error from the
+// broker is returned even though the revocation itself was stored, Create is
+// idempotent so callers may simply retry.
+func (r *broadcastRevokedList) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
+	if err := r.next.Create(ctx, jti, kind, exp); err != nil {
+		return err
+	}
+	return r.publish(ctx, Token{Jti: jti, Kind: kind, ExpiresAt: time.Now().Add(exp.Abs())})
+}
+
+func (r *broadcastRevokedList) RevokeMany(ctx context.Context, tokens []Token) error {
+	if err := r.next.RevokeMany(ctx, tokens); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, t := range tokens {
+		if !t.ExpiresAt.After(now) {
+			continue
+		}
+		if err := r.publish(ctx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *broadcastRevokedList) Find(ctx context.Context, jti string) (bool, error) {
+	return r.next.Find(ctx, jti)
+}
+
+func (r *broadcastRevokedList) publish(ctx context.Context, t Token) error {
+	msg, err := json.Marshal(Event{
+		Jti:       t.Jti,
+		Kind:      t.Kind.String(),
+		ExpiresAt: t.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return r.b.Publish(ctx, r.channel, string(msg))
+}
+
+// broadcastCutoffs publishes every cutoff recorded through the underlying
+// Cutoffs on the same channel as token revocations.
+type broadcastCutoffs struct {
+	next    Cutoffs
+	b       broker.Broker
+	channel string
+}
+
+func NewBroadcastCutoffs(next Cutoffs, b broker.Broker, channel string) *broadcastCutoffs {
+	return &broadcastCutoffs{
+		next:    next,
+		b:       b,
+		channel: channel,
+	}
+}
+
+func (r *broadcastCutoffs) Set(ctx context.Context, userID string, t time.Time) error {
+	if err := r.next.Set(ctx, userID, t); err != nil {
+		return err
+	}
+	msg, err := json.Marshal(Event{
+		UserID:    userID,
+		NotBefore: t,
+	})
+	if err != nil {
+		return err
+	}
+	return r.b.Publish(ctx, r.channel, string(msg))
+}
+
+func (r *broadcastCutoffs) Get(ctx context.Context, userID string) (time.Time, error) {
+	return r.next.Get(ctx, userID)
+}
+
+// Listen subscribes to channel and forwards revocation events to every
+// Observer until ctx is cancelled. Observers are resynced whenever the
+// subscription is (re)established so that no revocation is missed.
+func Listen(ctx context.Context, b broker.Broker, channel string, observers ...Observer) error {
+	return b.Subscribe(ctx, channel, &listener{observers: observers})
+}
+
+type listener struct {
+	observers []Observer
+}
+
+func (l *listener) OnSubscribe(ctx context.Context) {
+	for _, o := range l.observers {
+		if err := o.Resync(ctx); err != nil {
+			log.Printf("revoked: failed to resync observer: %v", err)
+		}
+	}
+}
+
+func (l *listener) OnMessage(ctx context.Context, message string) {
+	var e Event
+	if err := json.Unmarshal([]byte(message), &e); err != nil || (e.Jti == "") == (e.UserID == "") {
+		log.Printf("revoked: dropping malformed event: %q", message)
+		return
+	}
+	for _, o := range l.observers {
+		o.Observe(ctx, e)
+	}
+}
@@ -0,0 +1,195 @@
+package revoked
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/broker"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+type observerMock struct {
+	events  chan Event
+	resyncs chan struct{}
+}
+
+func (o *observerMock) Observe(ctx context.Context, e Event) {
+	o.events <- e
+}
+
+func (o *observerMock) Resync(ctx context.Context) error {
+	o.resyncs <- struct{}{}
+	return nil
+}
+
+func listenHelper(t *testing.T, b broker.Broker) *observerMock {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(globalContext)
+	t.Cleanup(cancel)
+
+	o := &observerMock{
+		events:  make(chan Event, 8),
+		resyncs: make(chan struct{}, 8),
+	}
+	go Listen(ctx, b, DefaultChannel, o)
+
+	select {
+	case <-o.resyncs:
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "timed out waiting for initial resync")
+	}
+	return o
+}
+
+func TestBroadcastCreate_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	o := listenHelper(t, b)
+	r := NewBroadcastRevokedList(testList.c, b, DefaultChannel)
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	select {
+	case e := <-o.events:
+		assert.Equal(t, "testJti", e.Jti)
+		assert.Equal(t, pb.TokenKind_TOKEN_KIND_ACCESS.String(), e.Kind)
+		assert.WithinDuration(t, time.Now().Add(config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS)), e.ExpiresAt, time.Second*5)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for event")
+	}
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestBroadcastCreate_Invalid(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	r := NewBroadcastRevokedList(testList.c, b, DefaultChannel)
+
+	err := r.Create(globalContext, "", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestBroadcastCreate_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	testList.m.SetError("failed to create")
+	defer testList.m.SetError("")
+
+	r := NewBroadcastRevokedList(testList.c, b, DefaultChannel)
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create")
+}
+
+func TestListen_Malformed(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	o := listenHelper(t, b)
+
+	err := b.Publish(globalContext, DefaultChannel, "not json")
+	assert.NoError(t, err)
+
+	err = b.Publish(globalContext, DefaultChannel, `{"jti":"otherJti","user_id":"1"}`)
+	assert.NoError(t, err)
+
+	err = b.Publish(globalContext, DefaultChannel, `{"jti":"testJti"}`)
+	assert.NoError(t, err)
+
+	select {
+	case e := <-o.events:
+		assert.Equal(t, "testJti", e.Jti)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for event")
+	}
+}
+
+func TestBroadcastRevokeMany_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	o := listenHelper(t, b)
+	r := NewBroadcastRevokedList(testList.c, b, DefaultChannel)
+
+	exp := time.Now().Add(time.Minute)
+	err := r.RevokeMany(globalContext, []Token{
+		{Jti: "expiredJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(-time.Minute)},
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: exp},
+	})
+	assert.NoError(t, err)
+
+	select {
+	case e := <-o.events:
+		assert.Equal(t, "testJti", e.Jti)
+		assert.True(t, exp.Equal(e.ExpiresAt))
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for event")
+	}
+}
+
+func TestBroadcastCutoffsSet_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	_ = insertTokenHelper(t)
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	o := listenHelper(t, b)
+	c := NewBroadcastCutoffs(NewStoreCutoffs(testStore), b, DefaultChannel)
+
+	now := time.Now()
+	err := c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	select {
+	case e := <-o.events:
+		assert.Empty(t, e.Jti)
+		assert.Equal(t, "1", e.UserID)
+		assert.True(t, now.Equal(e.NotBefore))
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for event")
+	}
+
+	v, err := c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, now, v, time.Second)
+}
+
+func TestBroadcastCutoffsSet_NotFound(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+
+	b := broker.NewRedisBroker(testList.m.Addr(), "")
+	defer b.Close()
+
+	c := NewBroadcastCutoffs(NewStoreCutoffs(testStore), b, DefaultChannel)
+
+	err := c.Set(globalContext, "does-not-exist", time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
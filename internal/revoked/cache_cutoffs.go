@@ -0,0 +1,75 @@
+package revoked
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/cache"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+const cutoffKeyPrefix = "cutoff:"
+
+// cacheCutoffs stores cutoffs as unix nanoseconds, with "0" recording that a
+// user has no cutoff. Entries outlive the longest lived token, after which a
+// cutoff can no longer reject anything.
+type cacheCutoffs struct {
+	c cache.Cache
+}
+
+func NewCacheCutoffs(c cache.Cache) *cacheCutoffs {
+	return &cacheCutoffs{c: c}
+}
+
+func (r *cacheCutoffs) Set(ctx context.Context, userID string, t time.Time) error {
+	if userID == "" {
+		return ErrInvalidKey
+	}
+	_, err := r.c.Set(ctx, cutoffKeyPrefix+userID, formatCutoff(t), config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_REFRESH))
+	return err
+}
+
+// Fill caches t only if no cutoff is cached yet, so that a value read from
+// the database can never overwrite a newer cutoff set concurrently.
+func (r *cacheCutoffs) Fill(ctx context.Context, userID string, t time.Time) error {
+	if userID == "" {
+		return ErrInvalidKey
+	}
+	_, err := r.c.SetNX(ctx, cutoffKeyPrefix+userID, formatCutoff(t), config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_REFRESH))
+	return err
+}
+
+// Delete removes the cached cutoff of a user, so that the next Get misses.
+func (r *cacheCutoffs) Delete(ctx context.Context, userID string) error {
+	if userID == "" {
+		return ErrInvalidKey
+	}
+	return r.c.Del(ctx, cutoffKeyPrefix+userID)
+}
+
+func (r *cacheCutoffs) Get(ctx context.Context, userID string) (time.Time, error) {
+	if userID == "" {
+		return time.Time{}, ErrInvalidKey
+	}
+	v, err := r.c.Get(ctx, cutoffKeyPrefix+userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if i == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(0, i), nil
+}
+
+func formatCutoff(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
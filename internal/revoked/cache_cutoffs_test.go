@@ -0,0 +1,129 @@
+package revoked
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gebhn/auth-service/internal/cache"
+)
+
+func newCacheCutoffsHelper(t *testing.T) *cacheCutoffs {
+	t.Helper()
+
+	c := cache.NewRedisCache(testList.m.Addr(), "")
+	t.Cleanup(func() { c.Close() })
+
+	return NewCacheCutoffs(c)
+}
+
+func TestCacheCutoffsSet_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	c := newCacheCutoffsHelper(t)
+
+	now := time.Now()
+	err := c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	v, err := c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(v))
+
+	err = c.Set(globalContext, "2", time.Time{})
+	assert.NoError(t, err)
+
+	v, err = c.Get(globalContext, "2")
+	assert.NoError(t, err)
+	assert.True(t, v.IsZero())
+}
+
+func TestCacheCutoffsSet_Invalid(t *testing.T) {
+	defer testList.m.FlushAll()
+	c := newCacheCutoffsHelper(t)
+
+	err := c.Set(globalContext, "", time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+
+	_, err = c.Get(globalContext, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestCacheCutoffsGet_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+	c := newCacheCutoffsHelper(t)
+	testList.m.SetError("failed to get")
+	defer testList.m.SetError("")
+
+	_, err := c.Get(globalContext, "1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get")
+}
+
+func TestCacheCutoffsFill_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	c := newCacheCutoffsHelper(t)
+
+	now := time.Now()
+	err := c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	err = c.Fill(globalContext, "1", time.Time{})
+	assert.NoError(t, err)
+
+	v, err := c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(v))
+}
+
+func TestIssuedBeforeCutoff_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	c := newCacheCutoffsHelper(t)
+
+	cutoff := time.Now()
+	ok, err := IssuedBeforeCutoff(globalContext, c, "1", cutoff.Add(-time.Hour))
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	err = c.Set(globalContext, "1", time.Time{})
+	assert.NoError(t, err)
+
+	ok, err = IssuedBeforeCutoff(globalContext, c, "1", cutoff.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	err = c.Set(globalContext, "1", cutoff)
+	assert.NoError(t, err)
+
+	ok, err = IssuedBeforeCutoff(globalContext, c, "1", cutoff.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Tokens issued earlier in the same second as the cutoff are revoked.
+	ok, err = IssuedBeforeCutoff(globalContext, c, "1", cutoff.Truncate(time.Second))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = IssuedBeforeCutoff(globalContext, c, "1", cutoff.Truncate(time.Second).Add(time.Second))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCacheCutoffsDelete_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	c := newCacheCutoffsHelper(t)
+
+	err := c.Set(globalContext, "1", time.Now())
+	assert.NoError(t, err)
+
+	err = c.Delete(globalContext, "1")
+	assert.NoError(t, err)
+
+	_, err = c.Get(globalContext, "1")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	err = c.Delete(globalContext, "")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
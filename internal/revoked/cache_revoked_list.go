@@ -2,7 +2,9 @@ package revoked
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gebhn/auth-service/api/pb"
@@ -10,12 +12,22 @@ import (
 	"github.com/gebhn/auth-service/internal/config"
 )
 
+// cacheKeyPrefix namespaces revocations. They used to be kept under the bare
+// jti, which Find still reads while REVOKED_CACHE_LEGACY_KEYS is set.
+const cacheKeyPrefix = "revoked:"
+
 type cacheRevokedList struct {
 	c cache.Cache
+	// legacy looks up a revocation under its bare jti when it is not found
+	// under cacheKeyPrefix.
+	legacy bool
 }
 
 func NewCacheRevokedList(c cache.Cache) *cacheRevokedList {
-	return &cacheRevokedList{c: c}
+	return &cacheRevokedList{
+		c:      c,
+		legacy: config.GetRevokedCacheLegacyKeys(),
+	}
 }
 
 func (r *cacheRevokedList) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
@@ -25,8 +37,8 @@ func (r *cacheRevokedList) Create(ctx context.Context, jti string, kind pb.Token
 	if exp.Abs() < config.GetTokenDuration(kind) {
 		return ErrInvalidDuration
 	}
-	if _, err := r.c.Set(ctx, jti, "1", exp); err != nil {
-		return err
+	if _, err := r.c.Set(ctx, cacheKeyPrefix+jti, "1", exp); err != nil {
+		return unavailable(err)
 	}
 	return nil
 }
@@ -35,9 +47,15 @@ func (r *cacheRevokedList) Find(ctx context.Context, jti string) (bool, error) {
 	if jti == "" {
 		return false, ErrInvalidKey
 	}
-	v, err := r.c.Get(ctx, jti)
+	v, err := r.c.Get(ctx, cacheKeyPrefix+jti)
+	if errors.Is(err, cache.ErrNotFound) && r.legacy {
+		v, err = r.c.Get(ctx, jti)
+	}
+	if errors.Is(err, cache.ErrNotFound) {
+		return false, nil
+	}
 	if err != nil {
-		return false, err
+		return false, unavailable(err)
 	}
 	i, err := strconv.Atoi(v)
 	if err != nil {
@@ -45,3 +63,37 @@ func (r *cacheRevokedList) Find(ctx context.Context, jti string) (bool, error) {
 	}
 	return i > 0, nil
 }
+
+func (r *cacheRevokedList) RevokeMany(ctx context.Context, tokens []Token) error {
+	now := time.Now()
+	ts, err := live(tokens, now)
+	if err != nil {
+		return err
+	}
+	entries := make([]cache.Entry, 0, len(ts))
+	for _, t := range ts {
+		entries = append(entries, cache.Entry{
+			Key:   cacheKeyPrefix + t.Jti,
+			Value: "1",
+			Exp:   t.ExpiresAt.Sub(now),
+		})
+	}
+	if err := r.c.SetMany(ctx, entries); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+// All only scans cacheKeyPrefix. Revocations still cached under their bare
+// jti are left out, the durable List which All is rebuilt from has them.
+func (r *cacheRevokedList) All(ctx context.Context) ([]string, error) {
+	keys, err := r.c.Keys(ctx, cacheKeyPrefix+"*")
+	if err != nil {
+		return nil, unavailable(err)
+	}
+	jtis := make([]string, 0, len(keys))
+	for _, k := range keys {
+		jtis = append(jtis, strings.TrimPrefix(k, cacheKeyPrefix))
+	}
+	return jtis, nil
+}
@@ -2,6 +2,7 @@ package revoked
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"os"
 	"testing"
@@ -11,7 +12,12 @@ import (
 	"github.com/gebhn/auth-service/api/pb"
 	"github.com/gebhn/auth-service/internal/cache"
 	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db"
+	"github.com/gebhn/auth-service/internal/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/tursodatabase/libsql-client-go/libsql"
 )
 
 type mockList struct {
@@ -21,6 +27,10 @@ type mockList struct {
 
 var testList *mockList
 
+var testStore store.Store
+
+var testDB *sql.DB
+
 var globalContext context.Context
 
 func TestMain(m *testing.M) {
@@ -40,6 +50,15 @@ func TestMain(m *testing.M) {
 		m: mr,
 	}
 
+	testDB = db.NewLibsqlConn("file::memory:?cache=shared", "")
+	defer testDB.Close()
+
+	if err := db.NewMigrator(testDB).Up(); err != nil {
+		log.Fatal(err)
+	}
+
+	testStore = store.NewSqlStore(testDB)
+
 	globalContext, cancelFunc = context.WithCancel(context.Background())
 	defer cancelFunc()
 
@@ -109,5 +128,112 @@ func TestFind_Fail(t *testing.T) {
 	ok, err := testList.c.Find(globalContext, "testJti")
 	assert.Error(t, err)
 	assert.False(t, ok)
+	assert.ErrorIs(t, err, ErrUnavailable)
 	assert.Contains(t, err.Error(), "failed to find")
 }
+
+func TestFind_NotFound(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	ok, err := testList.c.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAll_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	err := testList.c.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	err = testList.c.Create(globalContext, "testJti2", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	jtis, err := testList.c.All(globalContext)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"testJti", "testJti2"}, jtis)
+}
+
+func TestFind_Legacy(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	require.NoError(t, testList.m.Set("legacyJti", "1"))
+
+	ok, err := testList.c.Find(globalContext, "legacyJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	t.Setenv("REVOKED_CACHE_LEGACY_KEYS", "false")
+	c := cache.NewRedisCache(testList.m.Addr(), "")
+	defer c.Close()
+
+	ok, err = NewCacheRevokedList(c).Find(globalContext, "legacyJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAll_Namespaced(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	err := testList.c.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+	require.NoError(t, testList.m.Set("legacyJti", "1"))
+	require.NoError(t, testList.m.Set("cutoff:1", "1700000000"))
+	require.NoError(t, testList.m.Set("unrelated", "1"))
+
+	jtis, err := testList.c.All(globalContext)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"testJti"}, jtis)
+}
+
+func TestAll_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+	testList.m.SetError("failed to scan")
+	defer testList.m.SetError("")
+
+	jtis, err := testList.c.All(globalContext)
+	assert.Error(t, err)
+	assert.Nil(t, jtis)
+	assert.Contains(t, err.Error(), "failed to scan")
+}
+
+func TestRevokeMany_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	err := testList.c.RevokeMany(globalContext, []Token{
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+		{Jti: "testJti2", Kind: pb.TokenKind_TOKEN_KIND_REFRESH, ExpiresAt: time.Now().Add(time.Hour)},
+		{Jti: "expiredJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(-time.Minute)},
+	})
+	assert.NoError(t, err)
+
+	jtis, err := testList.c.All(globalContext)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"testJti", "testJti2"}, jtis)
+
+	assert.InDelta(t, time.Minute, testList.m.TTL(cacheKeyPrefix+"testJti"), float64(time.Second))
+	assert.InDelta(t, time.Hour, testList.m.TTL(cacheKeyPrefix+"testJti2"), float64(time.Second))
+}
+
+func TestRevokeMany_Invalid(t *testing.T) {
+	defer testList.m.FlushAll()
+
+	err := testList.c.RevokeMany(globalContext, []Token{
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+		{Jti: "", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestRevokeMany_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+	testList.m.SetError("failed to create")
+	defer testList.m.SetError("")
+
+	err := testList.c.RevokeMany(globalContext, []Token{
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Contains(t, err.Error(), "failed to create")
+}
@@ -0,0 +1,54 @@
+package revoked
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// compositeCutoffs persists cutoffs durably and caches them, including the
+// absence of a cutoff, so that checking a token rarely reaches the database.
+type compositeCutoffs struct {
+	cache   *cacheCutoffs
+	durable Cutoffs
+}
+
+func NewCompositeCutoffs(cache *cacheCutoffs, durable Cutoffs) *compositeCutoffs {
+	return &compositeCutoffs{
+		cache:   cache,
+		durable: durable,
+	}
+}
+
+func (r *compositeCutoffs) Set(ctx context.Context, userID string, t time.Time) error {
+	if err := r.durable.Set(ctx, userID, t); err != nil {
+		return err
+	}
+	// A cutoff left cached from before, including a cached absence of one,
+	// would keep accepting the tokens this cutoff rejects until it expires.
+	// It is dropped instead, so that Get falls back to the durable cutoff.
+	if err := r.cache.Set(ctx, userID, t); err != nil {
+		log.Printf("revoked: failed to cache cutoff for %s: %v", userID, err)
+		if err := r.cache.Delete(ctx, userID); err != nil {
+			return unavailable(err)
+		}
+	}
+	return nil
+}
+
+func (r *compositeCutoffs) Get(ctx context.Context, userID string) (time.Time, error) {
+	if userID == "" {
+		return time.Time{}, ErrInvalidKey
+	}
+	if t, err := r.cache.Get(ctx, userID); err == nil {
+		return t, nil
+	}
+	t, err := r.durable.Get(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := r.cache.Fill(ctx, userID, t); err != nil {
+		log.Printf("revoked: failed to cache cutoff for %s: %v", userID, err)
+	}
+	return t, nil
+}
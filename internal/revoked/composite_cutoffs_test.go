@@ -0,0 +1,119 @@
+package revoked
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/internal/cache"
+)
+
+// failingSetCache is a cache whose writes fail while everything else works.
+type failingSetCache struct {
+	cache.Cache
+}
+
+func (c failingSetCache) Set(ctx context.Context, key string, value string, exp time.Duration) (string, error) {
+	return "", errors.New("err")
+}
+
+func TestCompositeCutoffsSet_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	_ = insertTokenHelper(t)
+	cc := newCacheCutoffsHelper(t)
+	c := NewCompositeCutoffs(cc, NewStoreCutoffs(testStore))
+
+	now := time.Now()
+	err := c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	v, err := cc.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(v))
+
+	v, err = c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(v))
+}
+
+func TestCompositeCutoffsSet_NotFound(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	c := NewCompositeCutoffs(newCacheCutoffsHelper(t), NewStoreCutoffs(testStore))
+
+	err := c.Set(globalContext, "does-not-exist", time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCompositeCutoffsSet_CacheFail(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	_ = insertTokenHelper(t)
+	cc := newCacheCutoffsHelper(t)
+	c := NewCompositeCutoffs(NewCacheCutoffs(failingSetCache{cc.c}), NewStoreCutoffs(testStore))
+
+	// Cache the absence of a cutoff, which the failed write must not leave
+	// behind.
+	v, err := c.Get(globalContext, "1")
+	require.NoError(t, err)
+	require.True(t, v.IsZero())
+
+	now := time.Now()
+	err = c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	_, err = cc.Get(globalContext, "1")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	v, err = c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, now, v, time.Second)
+}
+
+func TestCompositeCutoffsSet_CacheUnavailable(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	_ = insertTokenHelper(t)
+	c := NewCompositeCutoffs(newCacheCutoffsHelper(t), NewStoreCutoffs(testStore))
+
+	testList.m.SetError("err")
+	defer testList.m.SetError("")
+
+	err := c.Set(globalContext, "1", time.Now())
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestCompositeCutoffsGet_Flushed(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	_ = insertTokenHelper(t)
+	cc := newCacheCutoffsHelper(t)
+	c := NewCompositeCutoffs(cc, NewStoreCutoffs(testStore))
+
+	now := time.Now()
+	err := c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	testList.m.FlushAll()
+
+	v, err := c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, now, v, time.Second)
+
+	v, err = cc.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, now, v, time.Second)
+}
+
+func TestCompositeCutoffsGet_Invalid(t *testing.T) {
+	c := NewCompositeCutoffs(newCacheCutoffsHelper(t), NewStoreCutoffs(testStore))
+
+	_, err := c.Get(globalContext, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
@@ -0,0 +1,68 @@
+package revoked
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+// compositeRevokedList keeps the durable List as the source of truth and
+// uses the cache as an accelerator. A revocation found in the cache is
+// answered immediately, anything else falls back to the durable List so that
+// a flushed or evicted cache never un-revokes a token. It is intended to sit
+// behind a bloomRevokedList, which keeps the fallback off the hot path.
+type compositeRevokedList struct {
+	cache   List
+	durable List
+}
+
+func NewCompositeRevokedList(cache List, durable List) *compositeRevokedList {
+	return &compositeRevokedList{
+		cache:   cache,
+		durable: durable,
+	}
+}
+
+func (r *compositeRevokedList) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
+	if err := r.durable.Create(ctx, jti, kind, exp); err != nil {
+		return err
+	}
+	// The revocation is already durable, a failed cache write only costs a
+	// fallback read later on.
+	if err := r.cache.Create(ctx, jti, kind, exp); err != nil {
+		log.Printf("revoked: failed to cache revocation of %s: %v", jti, err)
+	}
+	return nil
+}
+
+func (r *compositeRevokedList) RevokeMany(ctx context.Context, tokens []Token) error {
+	if err := r.durable.RevokeMany(ctx, tokens); err != nil {
+		return err
+	}
+	if err := r.cache.RevokeMany(ctx, tokens); err != nil {
+		log.Printf("revoked: failed to cache %d revocations: %v", len(tokens), err)
+	}
+	return nil
+}
+
+func (r *compositeRevokedList) Find(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, ErrInvalidKey
+	}
+	if ok, err := r.cache.Find(ctx, jti); err == nil && ok {
+		return true, nil
+	}
+	return r.durable.Find(ctx, jti)
+}
+
+func (r *compositeRevokedList) All(ctx context.Context) ([]string, error) {
+	if e, ok := r.durable.(Enumerator); ok {
+		return e.All(ctx)
+	}
+	if e, ok := r.cache.(Enumerator); ok {
+		return e.All(ctx)
+	}
+	return nil, ErrNotFound
+}
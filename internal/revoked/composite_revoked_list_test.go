@@ -0,0 +1,131 @@
+package revoked
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+func TestCompositeCreate_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	ok, err := testList.c.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = testStore.IsRevoked(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCompositeCreate_CacheFail(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	testList.m.SetError("failed to create")
+	defer testList.m.SetError("")
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	ok, err := testStore.IsRevoked(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCompositeCreate_Invalid(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.Create(globalContext, "", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestCompositeFind_Flushed(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	testList.m.FlushAll()
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCompositeFind_CacheFail(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	testList.m.SetError("failed to find")
+	defer testList.m.SetError("")
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = r.Find(globalContext, "otherJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCompositeFind_Invalid(t *testing.T) {
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	ok, err := r.Find(globalContext, "")
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestCompositeAll_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	testList.m.FlushAll()
+
+	jtis, err := r.All(globalContext)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"testJti"}, jtis)
+}
+
+func TestCompositeRevokeMany_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	r := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := r.RevokeMany(globalContext, []Token{
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	assert.NoError(t, err)
+
+	ok, err := testList.c.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = testStore.IsRevoked(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,176 @@
+package revoked
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/breaker"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+// maxLocalEntries bounds the revocations remembered by a guardedRevokedList.
+const maxLocalEntries = 10000
+
+// Policy decides how Find answers while the revocation backend is down.
+type Policy int
+
+const (
+	// FailClosed surfaces ErrUnavailable, which callers must treat as a
+	// revoked token.
+	FailClosed Policy = iota
+	// FailOpen accepts every token which is not known locally to be revoked.
+	FailOpen
+)
+
+// PolicyFor returns the configured Policy for a token kind. Only access
+// tokens, which are short lived, may fail open.
+func PolicyFor(kind pb.TokenKind) Policy {
+	if kind == pb.TokenKind_TOKEN_KIND_ACCESS && config.GetAccessTokenFailOpen() {
+		return FailOpen
+	}
+	return FailClosed
+}
+
+type GuardedStats struct {
+	breaker.Stats
+	FailedOpen   uint64
+	FailedClosed uint64
+}
+
+// guardedRevokedList applies a Policy whenever the underlying List is
+// unavailable, and stops calling it at all while the circuit breaker is open.
+// Revocations seen recently are remembered locally so that a fail-open List
+// still rejects them during an outage.
+type guardedRevokedList struct {
+	next   List
+	policy Policy
+	b      *breaker.Breaker
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	local map[string]time.Time
+
+	failedOpen   atomic.Uint64
+	failedClosed atomic.Uint64
+}
+
+func NewGuardedRevokedList(next List, policy Policy, b *breaker.Breaker, ttl time.Duration) *guardedRevokedList {
+	return &guardedRevokedList{
+		next:   next,
+		policy: policy,
+		b:      b,
+		ttl:    ttl,
+		local:  map[string]time.Time{},
+	}
+}
+
+// NewGuardedRevokedListFromConfig guards next for tokens of kind with the
+// Policy of PolicyFor and the configured circuit breaker and local cache.
+func NewGuardedRevokedListFromConfig(next List, kind pb.TokenKind) *guardedRevokedList {
+	b := breaker.New(config.GetRevokedBreakerThreshold(), config.GetRevokedBreakerCooldown())
+	return NewGuardedRevokedList(next, PolicyFor(kind), b, config.GetRevokedLocalCacheDuration())
+}
+
+// Create always reaches the underlying List, a revocation must never be
+// dropped because the breaker is open.
+func (r *guardedRevokedList) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
+	err := r.next.Create(ctx, jti, kind, exp)
+	r.resolve(ctx, err)
+	if err != nil {
+		return err
+	}
+	r.remember(jti, time.Now().Add(exp.Abs()))
+	return nil
+}
+
+func (r *guardedRevokedList) RevokeMany(ctx context.Context, tokens []Token) error {
+	err := r.next.RevokeMany(ctx, tokens)
+	r.resolve(ctx, err)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		r.remember(t.Jti, t.ExpiresAt)
+	}
+	return nil
+}
+
+func (r *guardedRevokedList) Find(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, ErrInvalidKey
+	}
+	if r.b.Allow() {
+		ok, err := r.next.Find(ctx, jti)
+		r.resolve(ctx, err)
+		if err == nil {
+			if ok {
+				r.remember(jti, time.Now().Add(r.ttl))
+			}
+			return ok, nil
+		}
+		if !errors.Is(err, ErrUnavailable) || ctx.Err() != nil {
+			return false, err
+		}
+	}
+
+	if r.policy == FailClosed {
+		r.failedClosed.Add(1)
+		return false, ErrUnavailable
+	}
+	r.failedOpen.Add(1)
+	return r.recall(jti), nil
+}
+
+func (r *guardedRevokedList) Stats() GuardedStats {
+	return GuardedStats{
+		Stats:        r.b.Stats(),
+		FailedOpen:   r.failedOpen.Load(),
+		FailedClosed: r.failedClosed.Load(),
+	}
+}
+
+// resolve reports the outcome of a call to next to the breaker, which always
+// ends a probe. Only ErrUnavailable counts as a failure, and only while the
+// caller has not given up on ctx.
+func (r *guardedRevokedList) resolve(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+		r.b.Success()
+	case errors.Is(err, ErrUnavailable) && ctx.Err() == nil:
+		r.b.Failure()
+	default:
+		r.b.Release()
+	}
+}
+
+func (r *guardedRevokedList) remember(jti string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.local) >= maxLocalEntries {
+		now := time.Now()
+		for k, v := range r.local {
+			if now.After(v) {
+				delete(r.local, k)
+			}
+		}
+		if len(r.local) >= maxLocalEntries {
+			return
+		}
+	}
+	if until.After(r.local[jti]) {
+		r.local[jti] = until
+	}
+}
+
+func (r *guardedRevokedList) recall(jti string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.local[jti]
+	return ok && time.Now().Before(until)
+}
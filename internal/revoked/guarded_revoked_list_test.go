@@ -0,0 +1,190 @@
+package revoked
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/breaker"
+	"github.com/gebhn/auth-service/internal/config"
+)
+
+func newGuardedHelper(t *testing.T, policy Policy) *guardedRevokedList {
+	t.Helper()
+
+	return NewGuardedRevokedList(testList.c, policy, breaker.New(2, time.Minute), time.Minute)
+}
+
+func TestGuardedFind_Success(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := newGuardedHelper(t, FailClosed)
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = r.Find(globalContext, "otherJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestGuardedFind_Invalid(t *testing.T) {
+	r := newGuardedHelper(t, FailClosed)
+
+	ok, err := r.Find(globalContext, "")
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestGuardedFind_FailClosed(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := newGuardedHelper(t, FailClosed)
+
+	testList.m.SetError("failed to find")
+	defer testList.m.SetError("")
+
+	for range 3 {
+		ok, err := r.Find(globalContext, "testJti")
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.False(t, ok)
+	}
+
+	s := r.Stats()
+	assert.Equal(t, breaker.StateOpen, s.State)
+	assert.Equal(t, uint64(3), s.FailedClosed)
+	assert.Zero(t, s.FailedOpen)
+}
+
+func TestGuardedFind_FromConfig(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := NewGuardedRevokedListFromConfig(testList.c, pb.TokenKind_TOKEN_KIND_REFRESH)
+
+	testList.m.SetError("failed to find")
+	defer testList.m.SetError("")
+
+	for range config.GetRevokedBreakerThreshold() {
+		ok, err := r.Find(globalContext, "testJti")
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.False(t, ok)
+	}
+
+	s := r.Stats()
+	assert.Equal(t, breaker.StateOpen, s.State)
+	assert.Equal(t, uint64(config.GetRevokedBreakerThreshold()), s.FailedClosed)
+}
+
+func TestGuardedFind_FailOpen(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := newGuardedHelper(t, FailOpen)
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	testList.m.SetError("failed to find")
+	defer testList.m.SetError("")
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = r.Find(globalContext, "otherJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	s := r.Stats()
+	assert.Equal(t, uint64(2), s.FailedOpen)
+	assert.Zero(t, s.FailedClosed)
+}
+
+func TestGuardedCreate_Fail(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := newGuardedHelper(t, FailOpen)
+
+	testList.m.SetError("failed to create")
+	defer testList.m.SetError("")
+
+	err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.ErrorIs(t, err, ErrUnavailable)
+
+	testList.m.SetError("")
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestPolicyFor_Success(t *testing.T) {
+	assert.Equal(t, FailClosed, PolicyFor(pb.TokenKind_TOKEN_KIND_REFRESH))
+
+	t.Setenv("ACCESS_TOKEN_FAIL_OPEN", "true")
+	assert.Equal(t, FailOpen, PolicyFor(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.Equal(t, FailClosed, PolicyFor(pb.TokenKind_TOKEN_KIND_REFRESH))
+
+	t.Setenv("ACCESS_TOKEN_FAIL_OPEN", "false")
+	assert.Equal(t, FailClosed, PolicyFor(pb.TokenKind_TOKEN_KIND_ACCESS))
+}
+
+func TestGuardedRevokeMany_FailOpen(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := newGuardedHelper(t, FailOpen)
+
+	err := r.RevokeMany(globalContext, []Token{
+		{Jti: "testJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	assert.NoError(t, err)
+
+	testList.m.SetError("failed to find")
+	defer testList.m.SetError("")
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestGuardedFind_ProbeReleased(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := NewGuardedRevokedList(testList.c, FailClosed, breaker.New(1, 0), time.Minute)
+
+	testList.m.SetError("failed to find")
+	_, err := r.Find(globalContext, "testJti")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, breaker.StateOpen, r.Stats().State)
+	testList.m.SetError("")
+
+	// The probe fails on a corrupt value rather than on the backend, which
+	// must not keep the breaker waiting on it forever.
+	require.NoError(t, testList.m.Set(cacheKeyPrefix+"corruptJti", "corrupt"))
+	_, err = r.Find(globalContext, "corruptJti")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnavailable)
+
+	ok, err := r.Find(globalContext, "testJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, breaker.StateClosed, r.Stats().State)
+}
+
+func TestGuardedFind_Canceled(t *testing.T) {
+	defer testList.m.FlushAll()
+	r := NewGuardedRevokedList(testList.c, FailClosed, breaker.New(1, time.Minute), time.Minute)
+
+	ctx, cancel := context.WithCancel(globalContext)
+	cancel()
+
+	_, err := r.Find(ctx, "testJti")
+	assert.Error(t, err)
+	err = r.Create(ctx, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.Error(t, err)
+
+	s := r.Stats()
+	assert.Equal(t, breaker.StateClosed, s.State)
+	assert.Zero(t, s.FailedClosed)
+}
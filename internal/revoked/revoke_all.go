@@ -0,0 +1,40 @@
+package revoked
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// RevokeAll sets a cutoff for userID, which rejects every token issued so
+// far including stateless access tokens, and then revokes each of the user's
+// live tokens in a single batch so that they are no longer listed as active.
+func RevokeAll(ctx context.Context, l List, c Cutoffs, s store.Store, userID string) error {
+	if userID == "" {
+		return ErrInvalidKey
+	}
+	if err := c.Set(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	tokens, err := s.GetTokensForUser(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return unavailable(err)
+	}
+
+	ts := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		ts = append(ts, Token{
+			Jti:       t.Jti,
+			Kind:      pb.TokenKind(pb.TokenKind_value[t.Kind]),
+			ExpiresAt: t.ExpiresAt,
+		})
+	}
+	return l.RevokeMany(ctx, ts)
+}
@@ -0,0 +1,69 @@
+package revoked
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func TestRevokeAll_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	token := insertTokenHelper(t)
+
+	second := sqlc.CreateTokenParams{
+		Jti:       "refreshJti2",
+		UserID:    token.UserID,
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		TokenHash: "hash2",
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, testStore.CreateToken(globalContext, second))
+
+	l := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+	c := NewStoreCutoffs(testStore)
+
+	err := RevokeAll(globalContext, l, c, testStore, token.UserID)
+	assert.NoError(t, err)
+
+	for _, jti := range []string{token.Jti, second.Jti} {
+		ok, err := l.Find(globalContext, jti)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	assert.True(t, testList.m.TTL(cacheKeyPrefix+second.Jti) <= time.Hour)
+
+	ok, err := IssuedBeforeCutoff(globalContext, c, token.UserID, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRevokeAll_NoTokens(t *testing.T) {
+	clearTables(t)
+	_ = insertTokenHelper(t)
+	_, err := testDB.Exec("delete from tokens;")
+	require.NoError(t, err)
+
+	err = RevokeAll(globalContext, testList.c, NewStoreCutoffs(testStore), testStore, "1")
+	assert.NoError(t, err)
+}
+
+func TestRevokeAll_Invalid(t *testing.T) {
+	err := RevokeAll(globalContext, testList.c, NewStoreCutoffs(testStore), testStore, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestRevokeAll_NotFound(t *testing.T) {
+	clearTables(t)
+
+	err := RevokeAll(globalContext, testList.c, NewStoreCutoffs(testStore), testStore, "does-not-exist")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,72 @@
+package revoked
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// ErrReused is returned by CheckReuse for a refresh token which was already
+// rotated or revoked.
+var ErrReused = errors.New("refresh token reused")
+
+// RevokeFamily revokes every live token of a family, that is every refresh
+// token rotated from the same login, in a single batch.
+func RevokeFamily(ctx context.Context, l List, s store.Store, familyID string) error {
+	if familyID == "" {
+		return ErrInvalidKey
+	}
+
+	tokens, err := s.GetTokensByFamily(ctx, &familyID)
+	if err != nil {
+		return unavailable(err)
+	}
+
+	ts := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		ts = append(ts, Token{
+			Jti:       t.Jti,
+			Kind:      pb.TokenKind(pb.TokenKind_value[t.Kind]),
+			ExpiresAt: t.ExpiresAt,
+		})
+	}
+	return l.RevokeMany(ctx, ts)
+}
+
+// CheckReuse is called with a refresh token presented for rotation. A token
+// which has already been revoked was presented before, by either its owner
+// or whoever stole it, so its whole family is revoked and ErrReused returned.
+// A token whose row was purged but whose revocation was archived is reused
+// as well, although its family can no longer be found.
+func CheckReuse(ctx context.Context, l List, s store.Store, jti string) error {
+	if jti == "" {
+		return ErrInvalidKey
+	}
+
+	t, err := s.GetTokenByJTI(ctx, jti)
+	if errors.Is(err, store.ErrNotFound) {
+		ok, err := s.IsRevoked(ctx, jti)
+		if err != nil {
+			return unavailable(err)
+		}
+		if ok {
+			return ErrReused
+		}
+		return ErrNotFound
+	}
+	if err != nil {
+		return unavailable(err)
+	}
+	if t.RevokedAt == nil {
+		return nil
+	}
+
+	if t.FamilyID != nil {
+		if err := RevokeFamily(ctx, l, s, *t.FamilyID); err != nil {
+			return err
+		}
+	}
+	return ErrReused
+}
@@ -0,0 +1,109 @@
+package revoked
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func insertFamilyHelper(t *testing.T, userID, familyID string, jtis ...string) {
+	t.Helper()
+
+	for _, jti := range jtis {
+		err := testStore.CreateToken(globalContext, sqlc.CreateTokenParams{
+			Jti:       jti,
+			UserID:    userID,
+			Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+			TokenHash: "hash" + jti,
+			IssuedAt:  time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+			FamilyID:  &familyID,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestRevokeFamily_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	token := insertTokenHelper(t)
+	insertFamilyHelper(t, token.UserID, "family", "first", "second")
+	insertFamilyHelper(t, token.UserID, "other", "third")
+
+	l := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+
+	err := RevokeFamily(globalContext, l, testStore, "family")
+	assert.NoError(t, err)
+
+	for jti, want := range map[string]bool{"first": true, "second": true, "third": false, token.Jti: false} {
+		ok, err := l.Find(globalContext, jti)
+		assert.NoError(t, err)
+		assert.Equal(t, want, ok, jti)
+	}
+	assert.True(t, testList.m.TTL(cacheKeyPrefix+"second") <= time.Hour)
+}
+
+func TestRevokeFamily_Invalid(t *testing.T) {
+	err := RevokeFamily(globalContext, testList.c, testStore, "")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestCheckReuse_Success(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+	token := insertTokenHelper(t)
+	insertFamilyHelper(t, token.UserID, "family", "rotated", "current")
+
+	l := NewCompositeRevokedList(testList.c, NewStoreRevokedList(testStore))
+	require.NoError(t, l.RevokeMany(globalContext, []Token{
+		{Jti: "rotated", Kind: pb.TokenKind_TOKEN_KIND_REFRESH, ExpiresAt: time.Now().Add(time.Hour)},
+	}))
+
+	err := CheckReuse(globalContext, l, testStore, "current")
+	assert.NoError(t, err)
+
+	ok, err := l.Find(globalContext, "current")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	err = CheckReuse(globalContext, l, testStore, "rotated")
+	assert.ErrorIs(t, err, ErrReused)
+
+	ok, err = l.Find(globalContext, "current")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = l.Find(globalContext, token.Jti)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCheckReuse_NotFound(t *testing.T) {
+	clearTables(t)
+
+	err := CheckReuse(globalContext, testList.c, testStore, "does-not-exist")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = CheckReuse(globalContext, testList.c, testStore, "")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestCheckReuse_Archived(t *testing.T) {
+	clearTables(t)
+	defer testList.m.FlushAll()
+
+	err := testStore.CreateRevocation(globalContext, sqlc.CreateRevocationParams{
+		Jti:       "purged",
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	err = CheckReuse(globalContext, testList.c, testStore, "purged")
+	assert.ErrorIs(t, err, ErrReused)
+}
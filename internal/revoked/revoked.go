@@ -3,6 +3,7 @@ package revoked
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gebhn/auth-service/api/pb"
@@ -12,11 +13,96 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrInvalidKey      = errors.New("invalid key")
 	ErrInvalidDuration = errors.New("invalid expiration date")
+	ErrUnavailable     = errors.New("revocation backend unavailable")
 )
 
+// Token describes a token to revoke along with its own expiry.
+type Token struct {
+	Jti       string
+	Kind      pb.TokenKind
+	ExpiresAt time.Time
+}
+
+// List records revoked jtis. Find reports (true, nil) for a revoked jti and
+// (false, nil) for one which is not revoked, a failing backend is reported
+// through an error wrapping ErrUnavailable.
 type List interface {
 	Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error
 	Find(ctx context.Context, jti string) (bool, error)
+	// RevokeMany records every token for no longer than its remaining
+	// lifetime. Tokens which have already expired are skipped.
+	RevokeMany(ctx context.Context, tokens []Token) error
+}
+
+// Enumerator is implemented by a List which can report every jti it
+// currently holds.
+type Enumerator interface {
+	All(ctx context.Context) ([]string, error)
+}
+
+// Cutoffs records, per user, the time before which every issued token is
+// considered revoked. A zero time means no cutoff has been set.
+type Cutoffs interface {
+	Set(ctx context.Context, userID string, t time.Time) error
+	Get(ctx context.Context, userID string) (time.Time, error)
+}
+
+// IssuedBeforeCutoff reports whether a token issued to userID at issuedAt
+// has been revoked by a cutoff, that is issued at or before it. Token
+// timestamps only carry whole seconds, so both are compared in seconds and a
+// token issued in the same second as the cutoff is revoked. Whatever
+// verifies a token must check it along with List.Find, a revoke-all is only
+// enforced through it for stateless tokens.
+func IssuedBeforeCutoff(ctx context.Context, c Cutoffs, userID string, issuedAt time.Time) (bool, error) {
+	t, err := c.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if t.IsZero() {
+		return false, nil
+	}
+	return !issuedAt.Truncate(time.Second).After(t.Truncate(time.Second)), nil
 }
 
-var _ List = (*cacheRevokedList)(nil)
+// Observer is notified of revocations made by any instance.
+type Observer interface {
+	Observe(ctx context.Context, e Event)
+	// Resync rebuilds any local state from the source of truth, it is called
+	// after events may have been missed.
+	Resync(ctx context.Context) error
+}
+
+var (
+	_ List       = (*cacheRevokedList)(nil)
+	_ Enumerator = (*cacheRevokedList)(nil)
+	_ List       = (*bloomRevokedList)(nil)
+	_ Observer   = (*bloomRevokedList)(nil)
+	_ List       = (*broadcastRevokedList)(nil)
+	_ Cutoffs    = (*broadcastCutoffs)(nil)
+	_ List       = (*storeRevokedList)(nil)
+	_ Enumerator = (*storeRevokedList)(nil)
+	_ List       = (*compositeRevokedList)(nil)
+	_ Enumerator = (*compositeRevokedList)(nil)
+	_ Cutoffs    = (*cacheCutoffs)(nil)
+	_ Cutoffs    = (*storeCutoffs)(nil)
+	_ Cutoffs    = (*compositeCutoffs)(nil)
+	_ List       = (*guardedRevokedList)(nil)
+)
+
+func unavailable(err error) error {
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// live validates tokens and returns those which have not expired by now.
+func live(tokens []Token, now time.Time) ([]Token, error) {
+	res := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Jti == "" {
+			return nil, ErrInvalidKey
+		}
+		if t.ExpiresAt.After(now) {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package revoked
+
+import (
+	"context"
+	"time"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// storeCutoffs persists cutoffs in the users.tokens_not_before column.
+type storeCutoffs struct {
+	s store.Store
+}
+
+func NewStoreCutoffs(s store.Store) *storeCutoffs {
+	return &storeCutoffs{s: s}
+}
+
+func (r *storeCutoffs) Set(ctx context.Context, userID string, t time.Time) error {
+	if userID == "" {
+		return ErrInvalidKey
+	}
+	n, err := r.s.SetUserNotBefore(ctx, sqlc.SetUserNotBeforeParams{
+		UserID:          userID,
+		TokensNotBefore: &t,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *storeCutoffs) Get(ctx context.Context, userID string) (time.Time, error) {
+	if userID == "" {
+		return time.Time{}, ErrInvalidKey
+	}
+	t, err := r.s.GetUserNotBefore(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t == nil {
+		return time.Time{}, nil
+	}
+	return *t, nil
+}
@@ -0,0 +1,48 @@
+package revoked
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreCutoffsSet_Success(t *testing.T) {
+	clearTables(t)
+	_ = insertTokenHelper(t)
+	c := NewStoreCutoffs(testStore)
+
+	v, err := c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.True(t, v.IsZero())
+
+	now := time.Now()
+	err = c.Set(globalContext, "1", now)
+	assert.NoError(t, err)
+
+	v, err = c.Get(globalContext, "1")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, now, v, time.Second)
+}
+
+func TestStoreCutoffsSet_Invalid(t *testing.T) {
+	clearTables(t)
+	c := NewStoreCutoffs(testStore)
+
+	err := c.Set(globalContext, "", time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+
+	_, err = c.Get(globalContext, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestStoreCutoffsSet_NotFound(t *testing.T) {
+	clearTables(t)
+	c := NewStoreCutoffs(testStore)
+
+	err := c.Set(globalContext, "does-not-exist", time.Now())
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
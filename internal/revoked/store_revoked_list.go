@@ -0,0 +1,97 @@
+package revoked
+
+import (
+	"context"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// storeRevokedList records revocations durably. Tokens which are persisted in
+// the tokens table are marked through revoked_at, any other jti is recorded in
+// the revocations table until it expires.
+type storeRevokedList struct {
+	s store.Store
+}
+
+func NewStoreRevokedList(s store.Store) *storeRevokedList {
+	return &storeRevokedList{s: s}
+}
+
+func (r *storeRevokedList) Create(ctx context.Context, jti string, kind pb.TokenKind, exp time.Duration) error {
+	if jti == "" {
+		return ErrInvalidKey
+	}
+	if exp.Abs() < config.GetTokenDuration(kind) {
+		return ErrInvalidDuration
+	}
+	if err := revoke(ctx, r.s, Token{Jti: jti, Kind: kind, ExpiresAt: time.Now().Add(exp.Abs())}); err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+func (r *storeRevokedList) Find(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, ErrInvalidKey
+	}
+	ok, err := r.s.IsRevoked(ctx, jti)
+	if err != nil {
+		return false, unavailable(err)
+	}
+	return ok, nil
+}
+
+func (r *storeRevokedList) RevokeMany(ctx context.Context, tokens []Token) error {
+	ts, err := live(tokens, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(ts) == 0 {
+		return nil
+	}
+	err = r.s.ExecTx(ctx, func(s store.Store) error {
+		for _, t := range ts {
+			if err := revoke(ctx, s, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return unavailable(err)
+	}
+	return nil
+}
+
+func (r *storeRevokedList) All(ctx context.Context) ([]string, error) {
+	rows, err := r.s.GetRevokedTokens(ctx)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+	jtis := make([]string, 0, len(rows))
+	for _, row := range rows {
+		jtis = append(jtis, row.Jti)
+	}
+	return jtis, nil
+}
+
+func revoke(ctx context.Context, s store.Store, t Token) error {
+	if t.Kind != pb.TokenKind_TOKEN_KIND_ACCESS {
+		n, err := s.RevokeToken(ctx, t.Jti)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+	}
+	return s.CreateRevocation(ctx, sqlc.CreateRevocationParams{
+		Jti:       t.Jti,
+		Kind:      t.Kind.String(),
+		ExpiresAt: t.ExpiresAt,
+	})
+}
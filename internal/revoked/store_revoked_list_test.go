@@ -0,0 +1,147 @@
+package revoked
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func clearTables(t *testing.T) {
+	t.Helper()
+
+	_, err := testDB.Exec("delete from revocations; delete from tokens; delete from users;")
+	require.NoError(t, err, "failed to clear tables")
+}
+
+func insertTokenHelper(t *testing.T) sqlc.CreateTokenParams {
+	t.Helper()
+
+	err := testStore.CreateUser(globalContext, sqlc.CreateUserParams{
+		UserID:       "1",
+		Username:     "username1",
+		Email:        "username1@mail.me",
+		PasswordHash: "pass",
+	})
+	require.NoError(t, err)
+
+	params := sqlc.CreateTokenParams{
+		Jti:       "refreshJti",
+		UserID:    "1",
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		TokenHash: "hash",
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_REFRESH)),
+	}
+	err = testStore.CreateToken(globalContext, params)
+	require.NoError(t, err)
+
+	return params
+}
+
+func TestStoreCreate_Success(t *testing.T) {
+	clearTables(t)
+	r := NewStoreRevokedList(testStore)
+	token := insertTokenHelper(t)
+
+	t.Run("Refresh Token", func(t *testing.T) {
+		err := r.Create(globalContext, token.Jti, pb.TokenKind_TOKEN_KIND_REFRESH, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_REFRESH))
+		assert.NoError(t, err)
+
+		row, err := testStore.GetTokenByJTI(globalContext, token.Jti)
+		assert.NoError(t, err)
+		assert.NotNil(t, row.RevokedAt)
+	})
+	t.Run("Access Token", func(t *testing.T) {
+		err := r.Create(globalContext, "accessJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+		assert.NoError(t, err)
+	})
+	t.Run("Unknown Refresh Token", func(t *testing.T) {
+		err := r.Create(globalContext, "unknownJti", pb.TokenKind_TOKEN_KIND_REFRESH, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_REFRESH))
+		assert.NoError(t, err)
+	})
+
+	jtis, err := r.All(globalContext)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{token.Jti, "accessJti", "unknownJti"}, jtis)
+}
+
+func TestStoreCreate_Invalid(t *testing.T) {
+	clearTables(t)
+	r := NewStoreRevokedList(testStore)
+
+	t.Run("Invalid JTI", func(t *testing.T) {
+		err := r.Create(globalContext, "", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+	})
+	t.Run("Invalid Duration", func(t *testing.T) {
+		err := r.Create(globalContext, "testJti", pb.TokenKind_TOKEN_KIND_ACCESS, time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrInvalidDuration.Error())
+	})
+}
+
+func TestStoreFind_Success(t *testing.T) {
+	clearTables(t)
+	r := NewStoreRevokedList(testStore)
+
+	ok, err := r.Find(globalContext, "accessJti")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	err = r.Create(globalContext, "accessJti", pb.TokenKind_TOKEN_KIND_ACCESS, config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_ACCESS))
+	assert.NoError(t, err)
+
+	ok, err = r.Find(globalContext, "accessJti")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestStoreFind_Invalid(t *testing.T) {
+	clearTables(t)
+	r := NewStoreRevokedList(testStore)
+
+	ok, err := r.Find(globalContext, "")
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+}
+
+func TestStoreRevokeMany_Success(t *testing.T) {
+	clearTables(t)
+	r := NewStoreRevokedList(testStore)
+	token := insertTokenHelper(t)
+
+	err := r.RevokeMany(globalContext, []Token{
+		{Jti: token.Jti, Kind: pb.TokenKind_TOKEN_KIND_REFRESH, ExpiresAt: token.ExpiresAt},
+		{Jti: "accessJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+		{Jti: "expiredJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(-time.Minute)},
+	})
+	assert.NoError(t, err)
+
+	jtis, err := r.All(globalContext)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{token.Jti, "accessJti"}, jtis)
+}
+
+func TestStoreRevokeMany_Invalid(t *testing.T) {
+	clearTables(t)
+	r := NewStoreRevokedList(testStore)
+
+	err := r.RevokeMany(globalContext, []Token{
+		{Jti: "accessJti", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+		{Jti: "", Kind: pb.TokenKind_TOKEN_KIND_ACCESS, ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInvalidKey.Error())
+
+	jtis, err := r.All(globalContext)
+	assert.NoError(t, err)
+	assert.Empty(t, jtis)
+}
@@ -0,0 +1,34 @@
+package rpcerr
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Interceptor turns error statuses embedded in responses into gRPC errors.
+// Legacy clients which only read the status enum keep receiving the
+// response, with a nil error, when legacy is set.
+type Interceptor struct {
+	legacy bool
+}
+
+func NewInterceptor(legacy bool) *Interceptor {
+	return &Interceptor{legacy: legacy}
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		res, err := handler(ctx, req)
+		if err != nil || i.legacy {
+			return res, err
+		}
+		if m, ok := res.(proto.Message); ok {
+			if err := FromResponse(m); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+}
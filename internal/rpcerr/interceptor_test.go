@@ -0,0 +1,45 @@
+package rpcerr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+func callHelper(legacy bool, res any) (any, error) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return res, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Register_FullMethodName}
+	return NewInterceptor(legacy).Unary()(context.Background(), &pb.RegisterRequest{}, info, handler)
+}
+
+func TestInterceptor_Error(t *testing.T) {
+	res := pb.RegisterResponse_builder{Status: pb.RegisterStatus_REGISTER_STATUS_ERROR_USERNAME_TAKEN.Enum()}.Build()
+
+	got, err := callHelper(false, res)
+	assert.Nil(t, got)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestInterceptor_Success(t *testing.T) {
+	res := pb.RegisterResponse_builder{Status: pb.RegisterStatus_REGISTER_STATUS_OK.Enum()}.Build()
+
+	got, err := callHelper(false, res)
+	assert.NoError(t, err)
+	assert.Same(t, res, got)
+}
+
+func TestInterceptor_Legacy(t *testing.T) {
+	res := pb.RegisterResponse_builder{Status: pb.RegisterStatus_REGISTER_STATUS_ERROR_USERNAME_TAKEN.Enum()}.Build()
+
+	got, err := callHelper(true, res)
+	assert.NoError(t, err)
+	assert.Same(t, res, got)
+}
@@ -0,0 +1,141 @@
+package rpcerr
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+// Domain is the ErrorInfo domain of every error returned by the service.
+const Domain = "auth-service"
+
+// rule describes the gRPC error of a status. field names the request field
+// at fault, retry how long a client should wait before trying again.
+type rule struct {
+	code  codes.Code
+	field string
+	retry time.Duration
+}
+
+// rules maps every error status to its gRPC error. An error status which is
+// missing is reported as codes.Internal.
+var rules = map[string]rule{
+	pb.RegisterStatus_REGISTER_STATUS_ERROR_USERNAME_TAKEN.String():    {code: codes.AlreadyExists, field: "username"},
+	pb.RegisterStatus_REGISTER_STATUS_ERROR_EMAIL_TAKEN.String():       {code: codes.AlreadyExists, field: "email"},
+	pb.RegisterStatus_REGISTER_STATUS_ERROR_PASSWORD_REJECTED.String(): {code: codes.InvalidArgument, field: "password"},
+
+	pb.LoginStatus_LOGIN_STATUS_ERROR_USERNAME_INVALID.String():  {code: codes.Unauthenticated},
+	pb.LoginStatus_LOGIN_STATUS_ERROR_EMAIL_INVALID.String():     {code: codes.Unauthenticated},
+	pb.LoginStatus_LOGIN_STATUS_ERROR_PASSWORD_INVALID.String():  {code: codes.Unauthenticated},
+	pb.LoginStatus_LOGIN_STATUS_ERROR_TOO_MANY_SESSIONS.String(): {code: codes.ResourceExhausted},
+
+	pb.UpdateStatus_UPDATE_STATUS_ERROR_USERNAME_INVALID.String(): {code: codes.InvalidArgument, field: "username"},
+	pb.UpdateStatus_UPDATE_STATUS_ERROR_EMAIL_INVALID.String():    {code: codes.InvalidArgument, field: "email"},
+	pb.UpdateStatus_UPDATE_STATUS_ERROR_VERSION_CONFLICT.String(): {code: codes.Aborted, field: "expected_version"},
+
+	pb.RefreshStatus_REFRESH_STATUS_ERROR_TOKEN_INVALID.String(): {code: codes.Unauthenticated},
+	pb.RefreshStatus_REFRESH_STATUS_ERROR_TOKEN_EXPIRED.String(): {code: codes.Unauthenticated},
+
+	pb.AccessStatus_ACCESS_STATUS_ERROR_TOKEN_INVALID.String(): {code: codes.Unauthenticated},
+	pb.AccessStatus_ACCESS_STATUS_ERROR_TOKEN_EXPIRED.String(): {code: codes.Unauthenticated},
+
+	pb.ChangePasswordStatus_CHANGE_PASSWORD_STATUS_ERROR_INVALID_PASSWORD.String():  {code: codes.InvalidArgument},
+	pb.ChangePasswordStatus_CHANGE_PASSWORD_STATUS_ERROR_INVALID_TOKEN.String():     {code: codes.Unauthenticated},
+	pb.ChangePasswordStatus_CHANGE_PASSWORD_STATUS_ERROR_PASSWORD_REJECTED.String(): {code: codes.InvalidArgument, field: "new_password"},
+
+	pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_ERROR_UNAVAILABLE.String(): {code: codes.Unavailable, retry: time.Second},
+
+	pb.ListSessionsStatus_LIST_SESSIONS_STATUS_ERROR_INVALID_PAGE_TOKEN.String(): {code: codes.InvalidArgument, field: "page_token"},
+
+	pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_ERROR_NOT_FOUND.String(): {code: codes.NotFound, field: "session_id"},
+}
+
+// FromResponse returns the gRPC error of the status embedded in res, or nil
+// when res reports success. The error carries an ErrorInfo whose reason is
+// the name of the status, along with BadRequest and RetryInfo details where
+// they apply.
+func FromResponse(res proto.Message) error {
+	v, ok := statusOf(res)
+	if !ok {
+		return nil
+	}
+	name := string(v.Name())
+
+	r, ok := rules[name]
+	if !ok {
+		if !strings.Contains(name, "_ERROR_") {
+			return nil
+		}
+		r = rule{code: codes.Internal}
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   name,
+		Domain:   Domain,
+		Metadata: map[string]string{"status": strconv.Itoa(int(v.Number()))},
+	}}
+	if r.field != "" {
+		details = append(details, badRequest(res, r.field, name))
+	}
+	if r.retry > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(r.retry)})
+	}
+	st, err := status.New(r.code, name).WithDetails(details...)
+	if err != nil {
+		return status.Error(r.code, name)
+	}
+	return st.Err()
+}
+
+// badRequest reports field as the culprit of the status name. A response listing its
+// password_violations reports each of them instead.
+func badRequest(res proto.Message, field string, name string) *errdetails.BadRequest {
+	br := &errdetails.BadRequest{}
+
+	m := res.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("password_violations"); fd != nil && fd.IsList() && fd.Kind() == protoreflect.EnumKind {
+		list := m.Get(fd).List()
+		for i := range list.Len() {
+			v := fd.Enum().Values().ByNumber(list.Get(i).Enum())
+			if v == nil {
+				continue
+			}
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: name,
+				Reason:      string(v.Name()),
+			})
+		}
+	}
+	if len(br.FieldViolations) == 0 {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: name,
+		})
+	}
+	return br
+}
+
+// statusOf returns the value of the status enum of res.
+func statusOf(res proto.Message) (protoreflect.EnumValueDescriptor, bool) {
+	if res == nil {
+		return nil, false
+	}
+	m := res.ProtoReflect()
+	fd := m.Descriptor().Fields().ByName("status")
+	if fd == nil || fd.Kind() != protoreflect.EnumKind {
+		return nil, false
+	}
+	v := fd.Enum().Values().ByNumber(m.Get(fd).Enum())
+	return v, v != nil
+}
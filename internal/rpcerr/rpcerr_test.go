@@ -0,0 +1,112 @@
+package rpcerr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+func detailsHelper(t *testing.T, err error) (*status.Status, map[string]any) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+
+	details := map[string]any{}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			details["info"] = d
+		case *errdetails.BadRequest:
+			details["bad_request"] = d
+		case *errdetails.RetryInfo:
+			details["retry"] = d
+		}
+	}
+	return st, details
+}
+
+func TestFromResponse_Success(t *testing.T) {
+	for _, res := range []proto.Message{
+		pb.LoginResponse_builder{Status: pb.LoginStatus_LOGIN_STATUS_OK.Enum()}.Build(),
+		pb.GetRevocationsResponse_builder{Status: pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_NOT_MODIFIED.Enum()}.Build(),
+		&pb.Session{},
+	} {
+		assert.NoError(t, FromResponse(res))
+	}
+}
+
+func TestFromResponse_BadRequest(t *testing.T) {
+	res := pb.UpdateResponse_builder{Status: pb.UpdateStatus_UPDATE_STATUS_ERROR_EMAIL_INVALID.Enum()}.Build()
+
+	st, details := detailsHelper(t, FromResponse(res))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	info := details["info"].(*errdetails.ErrorInfo)
+	assert.Equal(t, "UPDATE_STATUS_ERROR_EMAIL_INVALID", info.GetReason())
+	assert.Equal(t, Domain, info.GetDomain())
+	assert.Equal(t, "4", info.GetMetadata()["status"])
+
+	violations := details["bad_request"].(*errdetails.BadRequest).GetFieldViolations()
+	require.Len(t, violations, 1)
+	assert.Equal(t, "email", violations[0].GetField())
+	assert.Nil(t, details["retry"])
+}
+
+func TestFromResponse_PasswordViolations(t *testing.T) {
+	res := pb.ChangePasswordResponse_builder{
+		Status: pb.ChangePasswordStatus_CHANGE_PASSWORD_STATUS_ERROR_PASSWORD_REJECTED.Enum(),
+		PasswordViolations: []pb.PasswordViolation{
+			pb.PasswordViolation_PASSWORD_VIOLATION_TOO_SHORT,
+			pb.PasswordViolation_PASSWORD_VIOLATION_BREACHED,
+		},
+	}.Build()
+
+	st, details := detailsHelper(t, FromResponse(res))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	violations := details["bad_request"].(*errdetails.BadRequest).GetFieldViolations()
+	require.Len(t, violations, 2)
+	assert.Equal(t, "new_password", violations[0].GetField())
+	assert.Equal(t, "PASSWORD_VIOLATION_TOO_SHORT", violations[0].GetReason())
+	assert.Equal(t, "PASSWORD_VIOLATION_BREACHED", violations[1].GetReason())
+}
+
+func TestFromResponse_Retry(t *testing.T) {
+	res := pb.GetRevocationsResponse_builder{Status: pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_ERROR_UNAVAILABLE.Enum()}.Build()
+
+	st, details := detailsHelper(t, FromResponse(res))
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, time.Second, details["retry"].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+}
+
+func TestFromResponse_Unknown(t *testing.T) {
+	res := pb.LogoutResponse_builder{Status: pb.LogoutStatus_LOGOUT_STATUS_ERROR_UNKNOWN.Enum()}.Build()
+
+	st, details := detailsHelper(t, FromResponse(res))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "LOGOUT_STATUS_ERROR_UNKNOWN", details["info"].(*errdetails.ErrorInfo).GetReason())
+}
+
+func TestRules_Complete(t *testing.T) {
+	enums := pb.File_auth_service_proto.Enums()
+	for i := range enums.Len() {
+		values := enums.Get(i).Values()
+		for j := range values.Len() {
+			name := string(values.Get(j).Name())
+			if !strings.Contains(name, "_ERROR_") || strings.HasSuffix(name, "_ERROR_UNKNOWN") {
+				continue
+			}
+			assert.Contains(t, rules, name)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// ErrSessionExpired is returned when refreshing a session which has been
+// idle for too long or reached its maximum lifetime.
+var ErrSessionExpired = errors.New("session expired")
+
+// Profile bounds a session: it expires once idle for longer than Idle, and
+// at the latest MaxLifetime after the login which started it.
+type Profile struct {
+	Idle        time.Duration
+	MaxLifetime time.Duration
+}
+
+// Lifetimes holds the Profile of a regular session and the longer one of a
+// session whose user asked to be remembered.
+type Lifetimes struct {
+	Short Profile
+	Long  Profile
+}
+
+// LifetimesFromConfig returns the Lifetimes of SESSION_IDLE_TIMEOUT and
+// SESSION_MAX_LIFETIME, and of their REMEMBER_ME counterparts.
+func LifetimesFromConfig() Lifetimes {
+	return Lifetimes{
+		Short: Profile{
+			Idle:        config.GetSessionIdleTimeout(),
+			MaxLifetime: config.GetSessionMaxLifetime(),
+		},
+		Long: Profile{
+			Idle:        config.GetRememberMeIdleTimeout(),
+			MaxLifetime: config.GetRememberMeMaxLifetime(),
+		},
+	}
+}
+
+// Start sets the expiry of the refresh token created by a login from its
+// IssuedAt. ExpiresAt slides with every refresh whereas MaxExpiresAt does not,
+// the latter is the one to sign into the token itself.
+func (l Lifetimes) Start(p *sqlc.CreateTokenParams, remember bool) {
+	profile := l.profile(remember)
+	maxExpiresAt := p.IssuedAt.Add(profile.MaxLifetime)
+
+	p.ExpiresAt = earliest(p.IssuedAt.Add(profile.Idle), maxExpiresAt)
+	p.RememberMe = remember
+	p.MaxExpiresAt = &maxExpiresAt
+}
+
+// Extend slides the expiry of the session t by its idle timeout, capped by
+// its maximum lifetime, and returns the new expiry. Sessions created before
+// lifetimes were tracked are capped by their current expiry.
+func (l Lifetimes) Extend(ctx context.Context, s store.Store, t *sqlc.Token, now time.Time) (time.Time, error) {
+	maxExpiresAt := t.ExpiresAt
+	if t.MaxExpiresAt != nil {
+		maxExpiresAt = *t.MaxExpiresAt
+	}
+	expiresAt := earliest(now.Add(l.profile(t.RememberMe).Idle), maxExpiresAt)
+	if !t.ExpiresAt.After(now) || !expiresAt.After(now) {
+		return time.Time{}, ErrSessionExpired
+	}
+
+	n, err := s.ExtendToken(ctx, sqlc.ExtendTokenParams{
+		ExpiresAt:  expiresAt,
+		LastUsedAt: &now,
+		Jti:        t.Jti,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n == 0 {
+		return time.Time{}, ErrSessionExpired
+	}
+	return expiresAt, nil
+}
+
+func (l Lifetimes) profile(remember bool) Profile {
+	if remember {
+		return l.Long
+	}
+	return l.Short
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
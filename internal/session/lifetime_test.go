@@ -0,0 +1,119 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+var testLifetimes = Lifetimes{
+	Short: Profile{Idle: time.Hour, MaxLifetime: time.Hour * 4},
+	Long:  Profile{Idle: time.Hour * 24, MaxLifetime: time.Hour * 24 * 30},
+}
+
+func startSessionHelper(t *testing.T, s store.Store, issuedAt time.Time, remember bool) *sqlc.Token {
+	t.Helper()
+
+	p := sqlc.CreateTokenParams{
+		Jti:       "jti",
+		UserID:    "1",
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		TokenHash: "hash",
+		IssuedAt:  issuedAt,
+	}
+	testLifetimes.Start(&p, remember)
+	require.NoError(t, s.CreateToken(context.Background(), p))
+
+	token, err := s.GetTokenByJTI(context.Background(), p.Jti)
+	require.NoError(t, err)
+	return token
+}
+
+func TestLifetimesFromConfig(t *testing.T) {
+	t.Setenv("SESSION_IDLE_TIMEOUT", "1h")
+	t.Setenv("SESSION_MAX_LIFETIME", "4h")
+	t.Setenv("REMEMBER_ME_IDLE_TIMEOUT", "24h")
+	t.Setenv("REMEMBER_ME_MAX_LIFETIME", "720h")
+
+	assert.Equal(t, testLifetimes, LifetimesFromConfig())
+}
+
+func TestStart_Profiles(t *testing.T) {
+	now := time.Now()
+
+	p := sqlc.CreateTokenParams{IssuedAt: now}
+	testLifetimes.Start(&p, false)
+	assert.Equal(t, now.Add(time.Hour), p.ExpiresAt)
+	assert.Equal(t, now.Add(time.Hour*4), *p.MaxExpiresAt)
+	assert.False(t, p.RememberMe)
+
+	testLifetimes.Start(&p, true)
+	assert.Equal(t, now.Add(time.Hour*24), p.ExpiresAt)
+	assert.Equal(t, now.Add(time.Hour*24*30), *p.MaxExpiresAt)
+	assert.True(t, p.RememberMe)
+}
+
+func TestExtend_Slides(t *testing.T) {
+	_, s := newManagerHelper(t)
+	token := startSessionHelper(t, s, time.Now().Add(-time.Minute*30), false)
+
+	now := time.Now()
+	expiresAt, err := testLifetimes.Extend(context.Background(), s, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	token, err = s.GetTokenByJTI(context.Background(), token.Jti)
+	require.NoError(t, err)
+	assert.True(t, expiresAt.Equal(token.ExpiresAt))
+	assert.True(t, now.Equal(*token.LastUsedAt))
+}
+
+func TestExtend_MaxLifetime(t *testing.T) {
+	_, s := newManagerHelper(t)
+	lifetimes := Lifetimes{Short: Profile{Idle: time.Hour * 3, MaxLifetime: time.Hour * 4}}
+
+	p := sqlc.CreateTokenParams{
+		Jti:       "jti",
+		UserID:    "1",
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		TokenHash: "hash",
+		IssuedAt:  time.Now().Add(-time.Hour * 2),
+	}
+	lifetimes.Start(&p, false)
+	require.NoError(t, s.CreateToken(context.Background(), p))
+	token, err := s.GetTokenByJTI(context.Background(), p.Jti)
+	require.NoError(t, err)
+
+	expiresAt, err := lifetimes.Extend(context.Background(), s, token, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, *p.MaxExpiresAt, expiresAt)
+
+	_, err = lifetimes.Extend(context.Background(), s, token, p.MaxExpiresAt.Add(time.Second))
+	assert.ErrorIs(t, err, ErrSessionExpired)
+}
+
+func TestExtend_Idle(t *testing.T) {
+	_, s := newManagerHelper(t)
+	token := startSessionHelper(t, s, time.Now(), true)
+
+	_, err := testLifetimes.Extend(context.Background(), s, token, token.ExpiresAt.Add(time.Second))
+	assert.ErrorIs(t, err, ErrSessionExpired)
+}
+
+func TestExtend_Revoked(t *testing.T) {
+	_, s := newManagerHelper(t)
+	token := startSessionHelper(t, s, time.Now(), true)
+
+	_, err := s.RevokeToken(context.Background(), token.Jti)
+	require.NoError(t, err)
+
+	_, err = testLifetimes.Extend(context.Background(), s, token, time.Now())
+	assert.ErrorIs(t, err, ErrSessionExpired)
+}
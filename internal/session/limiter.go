@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+var (
+	ErrTooManySessions = errors.New("too many sessions")
+	ErrInvalidPolicy   = errors.New("invalid session limit policy")
+)
+
+// Policy decides what happens to a login which would exceed the limit.
+type Policy string
+
+const (
+	// PolicyReject refuses the new session.
+	PolicyReject Policy = "reject"
+	// PolicyEvict revokes the oldest sessions to make room for the new one.
+	PolicyEvict Policy = "evict"
+)
+
+// Limits caps the number of active sessions of a user, zero leaves it
+// unlimited. A client named in PerClient is limited on its own sessions
+// instead, e.g. to allow a single session per device type.
+type Limits struct {
+	Max       int
+	PerClient map[string]int
+	Policy    Policy
+}
+
+// Limiter enforces Limits when a session is created. Admit runs inside the
+// transaction creating the session, which should be serializable so that
+// concurrent logins of a user cannot both take the last slot. The sessions it
+// evicts are only revoked in the Store of the transaction, and have to be
+// passed to Publish once it has committed.
+type Limiter struct {
+	limits Limits
+	// list is the List evicted sessions are published to, typically the
+	// cached and broadcast one.
+	list revoked.List
+}
+
+func NewLimiter(limits Limits, list revoked.List) (*Limiter, error) {
+	if limits.Policy != PolicyReject && limits.Policy != PolicyEvict {
+		return nil, ErrInvalidPolicy
+	}
+	return &Limiter{
+		limits: limits,
+		list:   list,
+	}, nil
+}
+
+// NewLimiterFromConfig returns a Limiter enforcing MAX_SESSIONS,
+// MAX_SESSIONS_PER_CLIENT and SESSION_LIMIT_POLICY.
+func NewLimiterFromConfig(list revoked.List) (*Limiter, error) {
+	return NewLimiter(Limits{
+		Max:       config.GetMaxSessions(),
+		PerClient: config.GetMaxSessionsPerClient(),
+		Policy:    Policy(config.GetSessionLimitPolicy()),
+	}, list)
+}
+
+// Admit makes room for a new session of userID created by c. It returns
+// ErrTooManySessions when the limit is reached under PolicyReject, under
+// PolicyEvict it revokes as many of the oldest sessions as needed in s and
+// returns them. Nothing outside of s is written, so a transaction which is
+// rolled back or retried leaves no trace of the eviction.
+func (l *Limiter) Admit(ctx context.Context, s store.Store, userID string, c Client) ([]revoked.Token, error) {
+	name := truncate(c.Name)
+	limit, perClient := l.limits.PerClient[name]
+	if !perClient {
+		limit = l.limits.Max
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	tokens, err := s.ListSessions(ctx, sqlc.ListSessionsParams{
+		UserID:   userID,
+		PageSize: math.MaxInt32,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions are listed newest first, so the ones past the limit are the
+	// oldest.
+	active := make([]*sqlc.Token, 0, len(tokens))
+	for _, t := range tokens {
+		if !perClient || (t.ClientName != nil && *t.ClientName == name) {
+			active = append(active, t)
+		}
+	}
+	if len(active) < limit {
+		return nil, nil
+	}
+	if l.limits.Policy == PolicyReject {
+		return nil, ErrTooManySessions
+	}
+
+	evicted := make([]revoked.Token, 0, len(active)-limit+1)
+	for _, t := range active[limit-1:] {
+		evicted = append(evicted, revoked.Token{
+			Jti:       t.Jti,
+			Kind:      pb.TokenKind_TOKEN_KIND_REFRESH,
+			ExpiresAt: t.ExpiresAt,
+		})
+	}
+	if err := revoked.NewStoreRevokedList(s).RevokeMany(ctx, evicted); err != nil {
+		return nil, err
+	}
+	return evicted, nil
+}
+
+// Publish revokes the sessions evicted by Admit through the List of the
+// Limiter, once the transaction which evicted them has committed. Their rows
+// are already revoked, writing them again is harmless.
+func (l *Limiter) Publish(ctx context.Context, evicted []revoked.Token) error {
+	if len(evicted) == 0 {
+		return nil
+	}
+	return l.list.RevokeMany(ctx, evicted)
+}
@@ -0,0 +1,159 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// publishedList records the tokens published to it.
+type publishedList struct {
+	revoked.List
+	tokens []revoked.Token
+}
+
+func (l *publishedList) RevokeMany(ctx context.Context, tokens []revoked.Token) error {
+	l.tokens = append(l.tokens, tokens...)
+	return nil
+}
+
+func newLimiterHelper(t *testing.T, limits Limits) (*Limiter, *publishedList) {
+	t.Helper()
+
+	list := &publishedList{}
+	l, err := NewLimiter(limits, list)
+	require.NoError(t, err)
+	return l, list
+}
+
+func sessionsHelper(t *testing.T, s store.Store, userID string) []string {
+	t.Helper()
+
+	m := NewManager(s, revoked.NewStoreRevokedList(s))
+	sessions, _, err := m.list(context.Background(), userID, maxPageSize, "")
+	require.NoError(t, err)
+
+	jtis := make([]string, len(sessions))
+	for i, session := range sessions {
+		jtis[i] = session.GetSessionId()
+	}
+	return jtis
+}
+
+func TestNewLimiter_InvalidPolicy(t *testing.T) {
+	_, err := NewLimiter(Limits{Max: 1}, nil)
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestNewLimiterFromConfig(t *testing.T) {
+	t.Setenv("MAX_SESSIONS", "3")
+	t.Setenv("MAX_SESSIONS_PER_CLIENT", "cli=2")
+	t.Setenv("SESSION_LIMIT_POLICY", "reject")
+
+	_, s := newManagerHelper(t)
+	_ = insertSessionsHelper(t, s, "1", 2)
+	l, err := NewLimiterFromConfig(&publishedList{})
+	require.NoError(t, err)
+
+	_, err = l.Admit(context.Background(), s, "1", Client{})
+	assert.NoError(t, err)
+
+	_, err = l.Admit(context.Background(), s, "1", Client{Name: "cli"})
+	assert.ErrorIs(t, err, ErrTooManySessions)
+
+	t.Setenv("SESSION_LIMIT_POLICY", "drop")
+	_, err = NewLimiterFromConfig(nil)
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
+func TestAdmit_Unlimited(t *testing.T) {
+	_, s := newManagerHelper(t)
+	_ = insertSessionsHelper(t, s, "1", 3)
+	l, _ := newLimiterHelper(t, Limits{Policy: PolicyReject})
+
+	evicted, err := l.Admit(context.Background(), s, "1", Client{Name: "cli"})
+	assert.NoError(t, err)
+	assert.Empty(t, evicted)
+}
+
+func TestAdmit_Reject(t *testing.T) {
+	_, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 2)
+	_ = insertSessionsHelper(t, s, "2", 3)
+	l, _ := newLimiterHelper(t, Limits{Max: 3, Policy: PolicyReject})
+
+	_, err := l.Admit(context.Background(), s, "1", Client{})
+	assert.NoError(t, err)
+
+	_, err = l.Admit(context.Background(), s, "2", Client{})
+	assert.ErrorIs(t, err, ErrTooManySessions)
+	assert.Len(t, sessionsHelper(t, s, "2"), 3)
+	assert.Len(t, sessionsHelper(t, s, "1"), len(jtis))
+}
+
+func TestAdmit_Evict(t *testing.T) {
+	_, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 4)
+	l, list := newLimiterHelper(t, Limits{Max: 2, Policy: PolicyEvict})
+
+	var evicted []revoked.Token
+	err := s.ExecTx(context.Background(), func(s store.Store) error {
+		var err error
+		evicted, err = l.Admit(context.Background(), s, "1", Client{})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{jtis[3]}, sessionsHelper(t, s, "1"))
+	assert.Empty(t, list.tokens)
+
+	err = l.Publish(context.Background(), evicted)
+	require.NoError(t, err)
+	assert.Len(t, list.tokens, 3)
+	for i, token := range list.tokens {
+		assert.Equal(t, jtis[2-i], token.Jti)
+	}
+}
+
+func TestAdmit_EvictRolledBack(t *testing.T) {
+	_, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 4)
+	l, list := newLimiterHelper(t, Limits{Max: 2, Policy: PolicyEvict})
+
+	errRollback := errors.New("rollback")
+	err := s.ExecTx(context.Background(), func(s store.Store) error {
+		evicted, err := l.Admit(context.Background(), s, "1", Client{})
+		require.NoError(t, err)
+		require.Len(t, evicted, 3)
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	assert.Len(t, sessionsHelper(t, s, "1"), len(jtis))
+	assert.Empty(t, list.tokens)
+}
+
+func TestAdmit_PerClient(t *testing.T) {
+	_, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 2)
+	l, _ := newLimiterHelper(t, Limits{
+		Max:       1,
+		PerClient: map[string]int{"cli": 3},
+		Policy:    PolicyEvict,
+	})
+
+	evicted, err := l.Admit(context.Background(), s, "1", Client{Name: "cli"})
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	assert.Len(t, sessionsHelper(t, s, "1"), len(jtis))
+
+	evicted, err = l.Admit(context.Background(), s, "1", Client{Name: "web"})
+	require.NoError(t, err)
+	assert.Len(t, evicted, len(jtis))
+	assert.Empty(t, sessionsHelper(t, s, "1"))
+}
@@ -0,0 +1,180 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// cursor is the last session of a page, the next page starts right after it.
+type cursor struct {
+	IssuedAt time.Time `json:"i"`
+	Jti      string    `json:"j"`
+}
+
+// Manager lists and revokes sessions, that is the live refresh tokens of a
+// user.
+type Manager struct {
+	s store.Store
+	l revoked.List
+}
+
+func NewManager(s store.Store, l revoked.List) *Manager {
+	return &Manager{
+		s: s,
+		l: l,
+	}
+}
+
+func (m *Manager) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	res := &pb.ListSessionsResponse{}
+
+	sessions, next, err := m.list(ctx, req.GetUserId(), int(req.GetPageSize()), req.GetPageToken())
+	switch {
+	case errors.Is(err, ErrInvalidPageToken):
+		res.SetStatus(pb.ListSessionsStatus_LIST_SESSIONS_STATUS_ERROR_INVALID_PAGE_TOKEN)
+	case err != nil:
+		log.Printf("session: failed to list sessions: %v", err)
+		res.SetStatus(pb.ListSessionsStatus_LIST_SESSIONS_STATUS_ERROR_UNKNOWN)
+	default:
+		res.SetStatus(pb.ListSessionsStatus_LIST_SESSIONS_STATUS_OK)
+		res.SetSessions(sessions)
+		res.SetNextPageToken(next)
+	}
+	return res, nil
+}
+
+func (m *Manager) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	res := &pb.RevokeSessionResponse{}
+
+	err := m.revoke(ctx, req.GetUserId(), req.GetSessionId())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		res.SetStatus(pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_ERROR_NOT_FOUND)
+	case err != nil:
+		log.Printf("session: failed to revoke session: %v", err)
+		res.SetStatus(pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_ERROR_UNKNOWN)
+	default:
+		res.SetStatus(pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_OK)
+	}
+	return res, nil
+}
+
+// Touch records that the session was just used, e.g. to refresh.
+func (m *Manager) Touch(ctx context.Context, jti string) error {
+	now := time.Now()
+	_, err := m.s.TouchToken(ctx, sqlc.TouchTokenParams{LastUsedAt: &now, Jti: jti})
+	return err
+}
+
+// list returns a page of sessions along with the token of the next page,
+// which is empty on the last one.
+func (m *Manager) list(ctx context.Context, userID string, size int, token string) ([]*pb.Session, string, error) {
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	size = min(size, maxPageSize)
+
+	p := sqlc.ListSessionsParams{UserID: userID, PageSize: int64(size) + 1}
+	if token != "" {
+		c, err := decodeCursor(token)
+		if err != nil {
+			return nil, "", err
+		}
+		p.BeforeIssuedAt, p.BeforeJti = c.IssuedAt, c.Jti
+	}
+
+	tokens, err := m.s.ListSessions(ctx, p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(tokens) > size {
+		tokens = tokens[:size]
+		last := tokens[size-1]
+		if next, err = encodeCursor(cursor{IssuedAt: last.IssuedAt, Jti: last.Jti}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sessions := make([]*pb.Session, len(tokens))
+	for i, t := range tokens {
+		sessions[i] = toSession(t)
+	}
+	return sessions, next, nil
+}
+
+// revoke only revokes live refresh tokens owned by userID, anything else is
+// reported as not found so that callers cannot probe other users' sessions.
+func (m *Manager) revoke(ctx context.Context, userID string, jti string) error {
+	if userID == "" || jti == "" {
+		return ErrNotFound
+	}
+	t, err := m.s.GetTokenByJTI(ctx, jti)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if t.UserID != userID || t.Kind != pb.TokenKind_TOKEN_KIND_REFRESH.String() || t.RevokedAt != nil {
+		return ErrNotFound
+	}
+	return m.l.RevokeMany(ctx, []revoked.Token{{
+		Jti:       t.Jti,
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH,
+		ExpiresAt: t.ExpiresAt,
+	}})
+}
+
+func toSession(t *sqlc.Token) *pb.Session {
+	s := pb.Session_builder{
+		SessionId: proto.String(t.Jti),
+		CreatedAt: timestamppb.New(t.IssuedAt),
+		ExpiresAt: timestamppb.New(t.ExpiresAt),
+	}
+	s.ClientName = t.ClientName
+	s.IpAddress = t.IpAddress
+	s.UserAgent = t.UserAgent
+	if t.LastUsedAt != nil {
+		s.LastUsedAt = timestamppb.New(*t.LastUsedAt)
+	}
+	return s.Build()
+}
+
+func encodeCursor(c cursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(token string) (cursor, error) {
+	c := cursor{}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.IssuedAt.IsZero() || c.Jti == "" {
+		return c, ErrInvalidPageToken
+	}
+	return c, nil
+}
@@ -0,0 +1,148 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+func newManagerHelper(t *testing.T) (*Manager, store.Store) {
+	t.Helper()
+
+	s := store.NewMemoryStore()
+	for _, id := range []string{"1", "2"} {
+		err := s.CreateUser(context.Background(), sqlc.CreateUserParams{
+			UserID:       id,
+			Username:     "username" + id,
+			Email:        "username" + id + "@mail.me",
+			PasswordHash: "pass",
+		})
+		require.NoError(t, err)
+	}
+	return NewManager(s, revoked.NewStoreRevokedList(s)), s
+}
+
+func insertSessionsHelper(t *testing.T, s store.Store, userID string, n int) []string {
+	t.Helper()
+
+	jtis := make([]string, n)
+	for i := range jtis {
+		jtis[i] = fmt.Sprintf("jti-%s-%d", userID, i)
+		p := sqlc.CreateTokenParams{
+			Jti:       jtis[i],
+			UserID:    userID,
+			Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+			TokenHash: "hash",
+			IssuedAt:  time.Now().Add(time.Duration(i-n) * time.Second),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		Client{Name: "cli", IP: "10.0.0.1"}.Apply(&p)
+		require.NoError(t, s.CreateToken(context.Background(), p))
+	}
+	return jtis
+}
+
+func TestListSessions_Pages(t *testing.T) {
+	m, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 5)
+	_ = insertSessionsHelper(t, s, "2", 1)
+
+	var got []string
+	token := ""
+	for range 3 {
+		res, err := m.ListSessions(context.Background(), pb.ListSessionsRequest_builder{
+			UserId:    proto.String("1"),
+			PageSize:  proto.Int32(2),
+			PageToken: proto.String(token),
+		}.Build())
+		require.NoError(t, err)
+		require.Equal(t, pb.ListSessionsStatus_LIST_SESSIONS_STATUS_OK, res.GetStatus())
+
+		for _, session := range res.GetSessions() {
+			got = append(got, session.GetSessionId())
+			assert.Equal(t, "cli", session.GetClientName())
+			assert.Equal(t, "10.0.0.1", session.GetIpAddress())
+			assert.False(t, session.HasUserAgent())
+		}
+		token = res.GetNextPageToken()
+	}
+
+	assert.Empty(t, token)
+	assert.Equal(t, []string{jtis[4], jtis[3], jtis[2], jtis[1], jtis[0]}, got)
+}
+
+func TestListSessions_InvalidPageToken(t *testing.T) {
+	m, _ := newManagerHelper(t)
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		res, err := m.ListSessions(context.Background(), pb.ListSessionsRequest_builder{
+			UserId:    proto.String("1"),
+			PageToken: proto.String(token),
+		}.Build())
+		require.NoError(t, err)
+		assert.Equal(t, pb.ListSessionsStatus_LIST_SESSIONS_STATUS_ERROR_INVALID_PAGE_TOKEN, res.GetStatus(), token)
+	}
+}
+
+func TestRevokeSession_Success(t *testing.T) {
+	m, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 2)
+
+	res, err := m.RevokeSession(context.Background(), pb.RevokeSessionRequest_builder{
+		UserId:    proto.String("1"),
+		SessionId: proto.String(jtis[0]),
+	}.Build())
+	require.NoError(t, err)
+	assert.Equal(t, pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_OK, res.GetStatus())
+
+	list, err := m.ListSessions(context.Background(), pb.ListSessionsRequest_builder{
+		UserId: proto.String("1"),
+	}.Build())
+	require.NoError(t, err)
+	require.Len(t, list.GetSessions(), 1)
+	assert.Equal(t, jtis[1], list.GetSessions()[0].GetSessionId())
+
+	res, err = m.RevokeSession(context.Background(), pb.RevokeSessionRequest_builder{
+		UserId:    proto.String("1"),
+		SessionId: proto.String(jtis[0]),
+	}.Build())
+	require.NoError(t, err)
+	assert.Equal(t, pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_ERROR_NOT_FOUND, res.GetStatus())
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	m, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "2", 1)
+
+	for _, req := range []*pb.RevokeSessionRequest{
+		pb.RevokeSessionRequest_builder{UserId: proto.String("1"), SessionId: proto.String(jtis[0])}.Build(),
+		pb.RevokeSessionRequest_builder{UserId: proto.String("1"), SessionId: proto.String("does-not-exist")}.Build(),
+		pb.RevokeSessionRequest_builder{UserId: proto.String("1")}.Build(),
+	} {
+		res, err := m.RevokeSession(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, pb.RevokeSessionStatus_REVOKE_SESSION_STATUS_ERROR_NOT_FOUND, res.GetStatus())
+	}
+}
+
+func TestTouch_Success(t *testing.T) {
+	m, s := newManagerHelper(t)
+	jtis := insertSessionsHelper(t, s, "1", 1)
+
+	require.NoError(t, m.Touch(context.Background(), jtis[0]))
+
+	token, err := s.GetTokenByJTI(context.Background(), jtis[0])
+	require.NoError(t, err)
+	require.NotNil(t, token.LastUsedAt)
+	assert.WithinDuration(t, time.Now(), *token.LastUsedAt, time.Minute)
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+var (
+	ErrInvalidPageToken = errors.New("invalid page token")
+	ErrNotFound         = errors.New("session not found")
+)
+
+// ClientNameKey is the metadata key under which clients name themselves,
+// e.g. "ios" or "cli".
+const ClientNameKey = "x-client-name"
+
+// maxClientLength bounds each client field, they come straight from the
+// caller.
+const maxClientLength = 256
+
+// Client describes where a session was created from.
+type Client struct {
+	Name      string
+	IP        string
+	UserAgent string
+}
+
+// ClientFromContext describes the caller of an incoming gRPC call from its
+// metadata and peer address. Fields which are unknown are left empty.
+func ClientFromContext(ctx context.Context) Client {
+	c := Client{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		c.Name = first(md, ClientNameKey)
+		c.UserAgent = first(md, "user-agent")
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		c.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(c.IP); err == nil {
+			c.IP = host
+		}
+	}
+	return c
+}
+
+// Apply records c on a token about to be created.
+func (c Client) Apply(p *sqlc.CreateTokenParams) {
+	p.ClientName = nullable(c.Name)
+	p.IpAddress = nullable(c.IP)
+	p.UserAgent = nullable(c.UserAgent)
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	s = truncate(s)
+	return &s
+}
+
+func truncate(s string) string {
+	return s[:min(len(s), maxClientLength)]
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func TestClientFromContext_Success(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		ClientNameKey, "cli",
+		"user-agent", "grpc-go/1.72.2",
+	))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4242}})
+
+	c := ClientFromContext(ctx)
+	assert.Equal(t, Client{Name: "cli", IP: "10.0.0.1", UserAgent: "grpc-go/1.72.2"}, c)
+}
+
+func TestClientFromContext_Empty(t *testing.T) {
+	c := ClientFromContext(context.Background())
+	assert.Equal(t, Client{}, c)
+
+	p := sqlc.CreateTokenParams{}
+	c.Apply(&p)
+	assert.Nil(t, p.ClientName)
+	assert.Nil(t, p.IpAddress)
+	assert.Nil(t, p.UserAgent)
+}
+
+func TestClientApply_Truncates(t *testing.T) {
+	c := Client{Name: "cli", UserAgent: strings.Repeat("a", maxClientLength*2)}
+
+	p := sqlc.CreateTokenParams{}
+	c.Apply(&p)
+	assert.Equal(t, "cli", *p.ClientName)
+	assert.Nil(t, p.IpAddress)
+	assert.Len(t, *p.UserAgent, maxClientLength)
+}
@@ -0,0 +1,287 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/config"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+// generations is how many snapshots NewGeneratorFromConfig keeps, a verifier
+// that many intervals behind still receives a delta.
+const generations = 4
+
+type generation struct {
+	etag        string
+	generatedAt time.Time
+	tokens      map[string]*pb.RevokedToken
+	cutoffs     map[string]*pb.UserCutoff
+	signed      *pb.SignedRevocations
+
+	mu     sync.Mutex
+	deltas map[string]*pb.SignedRevocations
+}
+
+// Generator periodically builds a signed snapshot of every revoked token and
+// user cutoff which can still reject a live token. The last few generations
+// are kept so that verifiers which already hold one only receive a delta.
+type Generator struct {
+	s       store.Store
+	key     ed25519.PrivateKey
+	history int
+
+	mu          sync.RWMutex
+	generations []*generation
+}
+
+func NewGenerator(s store.Store, key ed25519.PrivateKey, history int) *Generator {
+	return &Generator{
+		s:       s,
+		key:     key,
+		history: max(history, 1),
+	}
+}
+
+// NewGeneratorFromConfig returns a Generator signing with the key of
+// REVOCATION_SIGNING_KEY, meant to Run every GetRevocationSnapshotInterval.
+func NewGeneratorFromConfig(s store.Store) (*Generator, error) {
+	key, err := ParseSigningKey(config.GetRevocationSigningKey())
+	if err != nil {
+		return nil, err
+	}
+	return NewGenerator(s, key, generations), nil
+}
+
+func (g *Generator) Generate(ctx context.Context) error {
+	snap, err := g.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	gen := &generation{
+		generatedAt: snap.GetGeneratedAt().AsTime(),
+		tokens:      make(map[string]*pb.RevokedToken, len(snap.GetTokens())),
+		cutoffs:     make(map[string]*pb.UserCutoff, len(snap.GetCutoffs())),
+		deltas:      map[string]*pb.SignedRevocations{},
+	}
+	for _, t := range snap.GetTokens() {
+		gen.tokens[t.GetJti()] = t
+	}
+	for _, c := range snap.GetCutoffs() {
+		gen.cutoffs[c.GetUserId()] = c
+	}
+
+	// The ETag only covers the content so that every instance agrees on it
+	// regardless of when the snapshot was generated.
+	content := pb.RevocationSnapshot_builder{
+		Tokens:  snap.GetTokens(),
+		Cutoffs: snap.GetCutoffs(),
+	}.Build()
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(content)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(b)
+	gen.etag = hex.EncodeToString(sum[:])
+
+	if gen.signed, err = sign(g.key, snap, false); err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if n := len(g.generations); n > 0 && g.generations[n-1].etag == gen.etag {
+		g.generations[n-1] = gen
+		return nil
+	}
+	g.generations = append(g.generations, gen)
+	if len(g.generations) > g.history {
+		g.generations = slices.Delete(g.generations, 0, len(g.generations)-g.history)
+	}
+	return nil
+}
+
+// Snapshot reads the current revocation set from the store without signing
+// or remembering it.
+func (g *Generator) Snapshot(ctx context.Context) (*pb.RevocationSnapshot, error) {
+	now := time.Now()
+	rows, err := g.s.GetRevokedTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	since := now.Add(-config.GetTokenDuration(pb.TokenKind_TOKEN_KIND_REFRESH))
+	users, err := g.s.GetUserCutoffs(ctx, &since)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]*pb.RevokedToken, len(rows))
+	for _, r := range rows {
+		tokens[r.Jti] = pb.RevokedToken_builder{
+			Jti:       proto.String(r.Jti),
+			TokenKind: pb.TokenKind(pb.TokenKind_value[r.Kind]).Enum(),
+			ExpiresAt: timestamppb.New(r.ExpiresAt),
+		}.Build()
+	}
+	cutoffs := make(map[string]*pb.UserCutoff, len(users))
+	for _, u := range users {
+		cutoffs[u.UserID] = pb.UserCutoff_builder{
+			UserId:    proto.String(u.UserID),
+			NotBefore: timestamppb.New(*u.TokensNotBefore),
+		}.Build()
+	}
+
+	return pb.RevocationSnapshot_builder{
+		GeneratedAt: timestamppb.New(now),
+		Tokens:      sortedTokens(tokens),
+		Cutoffs:     sortedCutoffs(cutoffs),
+	}.Build(), nil
+}
+
+// Run generates a snapshot right away, so that one is served as soon as the
+// service starts, and then every interval until ctx is cancelled.
+func (g *Generator) Run(ctx context.Context, interval time.Duration) {
+	if err := g.Generate(ctx); err != nil {
+		log.Printf("snapshot: failed to generate: %v", err)
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := g.Generate(ctx); err != nil {
+				log.Printf("snapshot: failed to generate: %v", err)
+			}
+		}
+	}
+}
+
+func (g *Generator) GetRevocations(ctx context.Context, req *pb.GetRevocationsRequest) (*pb.GetRevocationsResponse, error) {
+	res := &pb.GetRevocationsResponse{}
+
+	etag, signed, err := g.revocations(req.GetEtag())
+	switch {
+	case err != nil:
+		res.SetStatus(pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_ERROR_UNAVAILABLE)
+	case signed == nil:
+		res.SetStatus(pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_NOT_MODIFIED)
+		res.SetEtag(etag)
+	default:
+		res.SetStatus(pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_OK)
+		res.SetEtag(etag)
+		res.SetRevocations(signed)
+	}
+	return res, nil
+}
+
+// revocations returns the current ETag along with a delta from base when it
+// is still known, or the full snapshot otherwise. No payload is returned when
+// base is already current.
+func (g *Generator) revocations(base string) (string, *pb.SignedRevocations, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	n := len(g.generations)
+	if n == 0 {
+		return "", nil, ErrUnavailable
+	}
+	cur := g.generations[n-1]
+	base = strings.Trim(base, `"`)
+	if base == cur.etag {
+		return cur.etag, nil, nil
+	}
+	for _, prev := range g.generations[:n-1] {
+		if prev.etag == base {
+			d, err := g.delta(prev, cur)
+			if err != nil {
+				return "", nil, err
+			}
+			return cur.etag, d, nil
+		}
+	}
+	return cur.etag, cur.signed, nil
+}
+
+func (g *Generator) delta(prev *generation, cur *generation) (*pb.SignedRevocations, error) {
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if d, ok := cur.deltas[prev.etag]; ok {
+		return d, nil
+	}
+
+	added := map[string]*pb.RevokedToken{}
+	for jti, t := range cur.tokens {
+		if _, ok := prev.tokens[jti]; !ok {
+			added[jti] = t
+		}
+	}
+	removed := []string{}
+	for jti := range prev.tokens {
+		if _, ok := cur.tokens[jti]; !ok {
+			removed = append(removed, jti)
+		}
+	}
+	changed := map[string]*pb.UserCutoff{}
+	for id, c := range cur.cutoffs {
+		if p, ok := prev.cutoffs[id]; !ok || !p.GetNotBefore().AsTime().Equal(c.GetNotBefore().AsTime()) {
+			changed[id] = c
+		}
+	}
+	removedUsers := []string{}
+	for id := range prev.cutoffs {
+		if _, ok := cur.cutoffs[id]; !ok {
+			removedUsers = append(removedUsers, id)
+		}
+	}
+	slices.Sort(removed)
+	slices.Sort(removedUsers)
+
+	d, err := sign(g.key, pb.RevocationDelta_builder{
+		GeneratedAt:    timestamppb.New(cur.generatedAt),
+		BaseEtag:       proto.String(prev.etag),
+		AddedTokens:    sortedTokens(added),
+		RemovedJtis:    removed,
+		ChangedCutoffs: sortedCutoffs(changed),
+		RemovedUserIds: removedUsers,
+	}.Build(), true)
+	if err != nil {
+		return nil, err
+	}
+	cur.deltas[prev.etag] = d
+	return d, nil
+}
+
+func sortedTokens(m map[string]*pb.RevokedToken) []*pb.RevokedToken {
+	res := make([]*pb.RevokedToken, 0, len(m))
+	for _, jti := range slices.Sorted(maps.Keys(m)) {
+		res = append(res, m[jti])
+	}
+	return res
+}
+
+func sortedCutoffs(m map[string]*pb.UserCutoff) []*pb.UserCutoff {
+	res := make([]*pb.UserCutoff, 0, len(m))
+	for _, id := range slices.Sorted(maps.Keys(m)) {
+		res = append(res, m[id])
+	}
+	return res
+}
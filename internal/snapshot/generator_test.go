@@ -0,0 +1,232 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+	"github.com/gebhn/auth-service/internal/store"
+
+	_ "github.com/tursodatabase/libsql-client-go/libsql"
+)
+
+var testDB *sql.DB
+
+var testStore store.Store
+
+var testKey ed25519.PrivateKey
+
+func TestMain(m *testing.M) {
+	testDB = db.NewLibsqlConn("file::memory:?cache=shared", "")
+	defer testDB.Close()
+
+	if err := db.NewMigrator(testDB).Up(); err != nil {
+		log.Fatal(err)
+	}
+
+	testStore = store.NewSqlStore(testDB)
+	testKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+	os.Exit(m.Run())
+}
+
+func clearTables(t *testing.T) {
+	t.Helper()
+
+	_, err := testDB.Exec("delete from revocations; delete from tokens; delete from users;")
+	require.NoError(t, err, "failed to clear tables")
+}
+
+func revokeHelper(t *testing.T, jti string) {
+	t.Helper()
+
+	err := testStore.CreateRevocation(context.Background(), sqlc.CreateRevocationParams{
+		Jti:       jti,
+		Kind:      pb.TokenKind_TOKEN_KIND_ACCESS.String(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+}
+
+func cutoffHelper(t *testing.T, userID string) {
+	t.Helper()
+
+	err := testStore.CreateUser(context.Background(), sqlc.CreateUserParams{
+		UserID:       userID,
+		Username:     "username" + userID,
+		Email:        "username" + userID + "@mail.me",
+		PasswordHash: "pass",
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = testStore.SetUserNotBefore(context.Background(), sqlc.SetUserNotBeforeParams{
+		UserID:          userID,
+		TokensNotBefore: &now,
+	})
+	require.NoError(t, err)
+}
+
+func getHelper(t *testing.T, g *Generator, etag string) *pb.GetRevocationsResponse {
+	t.Helper()
+
+	req := &pb.GetRevocationsRequest{}
+	req.SetEtag(etag)
+
+	res, err := g.GetRevocations(context.Background(), req)
+	require.NoError(t, err)
+
+	return res
+}
+
+func TestGenerate_Success(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+	revokeHelper(t, "jti2")
+	cutoffHelper(t, "1")
+
+	g := NewGenerator(testStore, testKey, 3)
+	err := g.Generate(context.Background())
+	assert.NoError(t, err)
+
+	res := getHelper(t, g, "")
+	assert.Equal(t, pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_OK, res.GetStatus())
+	assert.NotEmpty(t, res.GetEtag())
+
+	snap, delta, err := Open(testKey.Public().(ed25519.PublicKey), res.GetRevocations())
+	assert.NoError(t, err)
+	assert.Nil(t, delta)
+	assert.Len(t, snap.GetTokens(), 2)
+	assert.Equal(t, "jti1", snap.GetTokens()[0].GetJti())
+	assert.Equal(t, pb.TokenKind_TOKEN_KIND_ACCESS, snap.GetTokens()[0].GetTokenKind())
+	assert.Len(t, snap.GetCutoffs(), 1)
+	assert.Equal(t, "1", snap.GetCutoffs()[0].GetUserId())
+	assert.WithinDuration(t, time.Now(), snap.GetGeneratedAt().AsTime(), time.Second*5)
+}
+
+func TestNewGeneratorFromConfig(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+	t.Setenv("REVOCATION_SIGNING_KEY", base64.StdEncoding.EncodeToString(testKey.Seed()))
+
+	g, err := NewGeneratorFromConfig(testStore)
+	require.NoError(t, err)
+	require.NoError(t, g.Generate(context.Background()))
+
+	snap, _, err := Open(testKey.Public().(ed25519.PublicKey), getHelper(t, g, "").GetRevocations())
+	assert.NoError(t, err)
+	assert.Len(t, snap.GetTokens(), 1)
+
+	t.Setenv("REVOCATION_SIGNING_KEY", "c2hvcnQ=")
+	_, err = NewGeneratorFromConfig(testStore)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestRun_Immediate(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+
+	g := NewGenerator(testStore, testKey, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go g.Run(ctx, time.Hour)
+
+	assert.Eventually(t, func() bool {
+		res, err := g.GetRevocations(ctx, &pb.GetRevocationsRequest{})
+		return err == nil && res.GetStatus() == pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_OK
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestGenerate_Fail(t *testing.T) {
+	c := db.NewLibsqlConn("file::memory:", "")
+	g := NewGenerator(store.NewSqlStore(c), testKey, 3)
+
+	err := g.Generate(context.Background())
+	assert.Error(t, err)
+
+	res := getHelper(t, g, "")
+	assert.Equal(t, pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_ERROR_UNAVAILABLE, res.GetStatus())
+}
+
+func TestGetRevocations_NotModified(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+
+	g := NewGenerator(testStore, testKey, 3)
+	require.NoError(t, g.Generate(context.Background()))
+
+	etag := getHelper(t, g, "").GetEtag()
+
+	require.NoError(t, g.Generate(context.Background()))
+
+	res := getHelper(t, g, etag)
+	assert.Equal(t, pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_NOT_MODIFIED, res.GetStatus())
+	assert.Equal(t, etag, res.GetEtag())
+	assert.False(t, res.HasRevocations())
+}
+
+func TestGetRevocations_Delta(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+	revokeHelper(t, "jti2")
+
+	g := NewGenerator(testStore, testKey, 3)
+	require.NoError(t, g.Generate(context.Background()))
+
+	etag := getHelper(t, g, "").GetEtag()
+
+	_, err := testDB.Exec("delete from revocations where jti = 'jti1'")
+	require.NoError(t, err)
+	revokeHelper(t, "jti3")
+	cutoffHelper(t, "1")
+
+	require.NoError(t, g.Generate(context.Background()))
+
+	res := getHelper(t, g, etag)
+	assert.Equal(t, pb.GetRevocationsStatus_GET_REVOCATIONS_STATUS_OK, res.GetStatus())
+	assert.NotEqual(t, etag, res.GetEtag())
+
+	snap, delta, err := Open(testKey.Public().(ed25519.PublicKey), res.GetRevocations())
+	assert.NoError(t, err)
+	assert.Nil(t, snap)
+	assert.Equal(t, etag, delta.GetBaseEtag())
+	assert.Len(t, delta.GetAddedTokens(), 1)
+	assert.Equal(t, "jti3", delta.GetAddedTokens()[0].GetJti())
+	assert.Equal(t, []string{"jti1"}, delta.GetRemovedJtis())
+	assert.Len(t, delta.GetChangedCutoffs(), 1)
+	assert.Empty(t, delta.GetRemovedUserIds())
+
+	t.Run("Unknown ETag", func(t *testing.T) {
+		res := getHelper(t, g, "unknown")
+		snap, _, err := Open(testKey.Public().(ed25519.PublicKey), res.GetRevocations())
+		assert.NoError(t, err)
+		assert.Len(t, snap.GetTokens(), 2)
+	})
+}
+
+func TestGetRevocations_History(t *testing.T) {
+	clearTables(t)
+
+	g := NewGenerator(testStore, testKey, 2)
+	require.NoError(t, g.Generate(context.Background()))
+	etag := getHelper(t, g, "").GetEtag()
+
+	revokeHelper(t, "jti1")
+	require.NoError(t, g.Generate(context.Background()))
+	revokeHelper(t, "jti2")
+	require.NoError(t, g.Generate(context.Background()))
+
+	res := getHelper(t, g, etag)
+	assert.Equal(t, pb.SignedRevocations_Snapshot_case, res.GetRevocations().WhichPayload())
+}
@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ServeHTTP serves the current revocations as a serialized SignedRevocations.
+// A client which still holds an earlier snapshot passes its ETag through the
+// since query parameter to receive a delta instead, and If-None-Match is
+// honoured as usual.
+func (g *Generator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	etag, signed, err := g.revocations(r.URL.Query().Get("since"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("ETag", strconv.Quote(etag))
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if signed == nil || strings.Trim(r.Header.Get("If-None-Match"), `"`) == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	b, err := proto.Marshal(signed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(b)
+}
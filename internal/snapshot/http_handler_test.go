@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+func serveHelper(t *testing.T, g *Generator, method string, target string, inm string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, nil)
+	if inm != "" {
+		req.Header.Set("If-None-Match", inm)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+
+	return rec.Result()
+}
+
+func TestServeHTTP_Success(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+
+	g := NewGenerator(testStore, testKey, 3)
+	require.NoError(t, g.Generate(context.Background()))
+
+	res := serveHelper(t, g, http.MethodGet, "/revocations", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/x-protobuf", res.Header.Get("Content-Type"))
+	assert.NotEmpty(t, res.Header.Get("ETag"))
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	signed := &pb.SignedRevocations{}
+	require.NoError(t, proto.Unmarshal(b, signed))
+
+	snap, _, err := Open(testKey.Public().(ed25519.PublicKey), signed)
+	assert.NoError(t, err)
+	assert.Len(t, snap.GetTokens(), 1)
+}
+
+func TestServeHTTP_NotModified(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+
+	g := NewGenerator(testStore, testKey, 3)
+	require.NoError(t, g.Generate(context.Background()))
+
+	etag := serveHelper(t, g, http.MethodGet, "/revocations", "").Header.Get("ETag")
+
+	res := serveHelper(t, g, http.MethodGet, "/revocations", etag)
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	res = serveHelper(t, g, http.MethodGet, "/revocations?since="+etag, "")
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+}
+
+func TestServeHTTP_Delta(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+
+	g := NewGenerator(testStore, testKey, 3)
+	require.NoError(t, g.Generate(context.Background()))
+
+	etag, err := strconv.Unquote(serveHelper(t, g, http.MethodGet, "/revocations", "").Header.Get("ETag"))
+	require.NoError(t, err)
+
+	revokeHelper(t, "jti2")
+	require.NoError(t, g.Generate(context.Background()))
+
+	res := serveHelper(t, g, http.MethodGet, "/revocations?since="+etag, "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	signed := &pb.SignedRevocations{}
+	require.NoError(t, proto.Unmarshal(b, signed))
+
+	_, delta, err := Open(testKey.Public().(ed25519.PublicKey), signed)
+	assert.NoError(t, err)
+	assert.Len(t, delta.GetAddedTokens(), 1)
+
+	t.Run("Stale If-None-Match", func(t *testing.T) {
+		res := serveHelper(t, g, http.MethodGet, "/revocations", strconv.Quote(etag))
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
+
+func TestServeHTTP_Invalid(t *testing.T) {
+	g := NewGenerator(testStore, testKey, 3)
+
+	res := serveHelper(t, g, http.MethodGet, "/revocations", "")
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	res = serveHelper(t, g, http.MethodPost, "/revocations", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
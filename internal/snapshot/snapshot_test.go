@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+func TestParseSigningKey_Success(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+
+	key, err := ParseSigningKey(base64.StdEncoding.EncodeToString(seed))
+	assert.NoError(t, err)
+	assert.Equal(t, ed25519.NewKeyFromSeed(seed), key)
+}
+
+func TestParseSigningKey_Invalid(t *testing.T) {
+	_, err := ParseSigningKey("not base64")
+	assert.Error(t, err)
+
+	_, err = ParseSigningKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	signed, err := sign(testKey, &pb.RevocationSnapshot{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, KeyID(testKey.Public().(ed25519.PublicKey)), signed.GetKeyId())
+
+	other := ed25519.NewKeyFromSeed([]byte("01234567890123456789012345678901"))
+
+	t.Run("Wrong Key", func(t *testing.T) {
+		_, _, err := Open(other.Public().(ed25519.PublicKey), signed)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+	t.Run("Tampered Payload", func(t *testing.T) {
+		tampered := &pb.SignedRevocations{}
+		tampered.SetSnapshot(append(signed.GetSnapshot(), 0))
+		tampered.SetSignature(signed.GetSignature())
+
+		_, _, err := Open(testKey.Public().(ed25519.PublicKey), tampered)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+	t.Run("Delta As Snapshot", func(t *testing.T) {
+		// A delta with no changes marshals to the same bytes as an empty
+		// snapshot, only the tag tells their signatures apart.
+		delta, err := sign(testKey, &pb.RevocationDelta{}, true)
+		require.NoError(t, err)
+
+		swapped := &pb.SignedRevocations{}
+		swapped.SetSnapshot(delta.GetDelta())
+		swapped.SetSignature(delta.GetSignature())
+
+		_, _, err = Open(testKey.Public().(ed25519.PublicKey), swapped)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+
+		_, got, err := Open(testKey.Public().(ed25519.PublicKey), delta)
+		assert.NoError(t, err)
+		assert.NotNil(t, got)
+	})
+	t.Run("Missing Payload", func(t *testing.T) {
+		_, _, err := Open(testKey.Public().(ed25519.PublicKey), &pb.SignedRevocations{})
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
@@ -0,0 +1,214 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/revoked"
+)
+
+// subscriberBuffer bounds the events queued for a single stream, a stream
+// which falls further behind is closed and has to resume.
+const subscriberBuffer = 256
+
+var _ revoked.Observer = (*Watcher)(nil)
+
+type subscriber struct {
+	ch chan *pb.WatchRevocationsResponse
+}
+
+// Watcher fans revocation events out to WatchRevocations streams. Recent
+// events are numbered and kept so that a client reconnecting with a resume
+// token only receives what it missed. Resume tokens are only meaningful to
+// the instance which issued them, any other token is answered with a full
+// snapshot.
+type Watcher struct {
+	g       *Generator
+	history int
+
+	mu     sync.Mutex
+	epoch  string
+	seq    uint64
+	events []*pb.WatchRevocationsResponse
+	subs   map[*subscriber]struct{}
+}
+
+func NewWatcher(g *Generator, history int) *Watcher {
+	return &Watcher{
+		g:       g,
+		history: max(history, 1),
+		epoch:   newEpoch(),
+		subs:    map[*subscriber]struct{}{},
+	}
+}
+
+func (w *Watcher) Observe(ctx context.Context, e revoked.Event) {
+	res := &pb.WatchRevocationsResponse{}
+	switch {
+	case e.Jti != "":
+		res.SetToken(pb.RevokedToken_builder{
+			Jti:       proto.String(e.Jti),
+			TokenKind: pb.TokenKind(pb.TokenKind_value[e.Kind]).Enum(),
+			ExpiresAt: timestamppb.New(e.ExpiresAt),
+		}.Build())
+	case e.UserID != "":
+		c := &pb.UserCutoff{}
+		c.SetUserId(e.UserID)
+		if !e.NotBefore.IsZero() {
+			c.SetNotBefore(timestamppb.New(e.NotBefore))
+		}
+		res.SetCutoff(c)
+	default:
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	res.SetResumeToken(w.token(w.seq))
+	w.events = append(w.events, res)
+	if len(w.events) > w.history {
+		w.events = w.events[len(w.events)-w.history:]
+	}
+	for s := range w.subs {
+		select {
+		case s.ch <- res:
+		default:
+			w.drop(s)
+		}
+	}
+}
+
+// Resync starts a new epoch, since events may have been missed no earlier
+// resume token can be trusted and every open stream is closed.
+func (w *Watcher) Resync(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.epoch = newEpoch()
+	w.seq = 0
+	w.events = nil
+	for s := range w.subs {
+		w.drop(s)
+	}
+	return nil
+}
+
+func (w *Watcher) WatchRevocations(req *pb.WatchRevocationsRequest, stream grpc.ServerStreamingServer[pb.WatchRevocationsResponse]) error {
+	ctx := stream.Context()
+
+	s, token, missed, ok := w.subscribe(req.GetResumeToken())
+	defer w.unsubscribe(s)
+
+	first := &pb.WatchRevocationsResponse{}
+	first.SetResumeToken(token)
+	if ok {
+		first.SetResumed(true)
+	} else {
+		// The subscription is registered before the snapshot is read, so
+		// nothing revoked in between is lost. Events already covered by the
+		// snapshot are simply received twice.
+		snap, err := w.g.Snapshot(ctx)
+		if err != nil {
+			return status.Error(codes.Unavailable, "failed to read revocations")
+		}
+		first.SetSnapshot(snap)
+	}
+	if err := stream.Send(first); err != nil {
+		return err
+	}
+	for _, res := range missed {
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case res, open := <-s.ch:
+			if !open {
+				return status.Error(codes.Aborted, "revocation stream interrupted, resume to continue")
+			}
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// subscribe registers a new subscriber and returns the resume token it
+// starts from. When resume is still valid the events missed since then are
+// returned along with true.
+func (w *Watcher) subscribe(resume string) (*subscriber, string, []*pb.WatchRevocationsResponse, bool) {
+	s := &subscriber{ch: make(chan *pb.WatchRevocationsResponse, subscriberBuffer)}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subs[s] = struct{}{}
+	token := w.token(w.seq)
+
+	seq, ok := w.parse(resume)
+	if !ok {
+		return s, token, nil, false
+	}
+	missed := w.seq - seq
+	if missed > uint64(len(w.events)) {
+		return s, token, nil, false
+	}
+	return s, resume, slices.Clone(w.events[uint64(len(w.events))-missed:]), true
+}
+
+func (w *Watcher) unsubscribe(s *subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.subs[s]; ok {
+		delete(w.subs, s)
+		close(s.ch)
+	}
+}
+
+// drop must be called with mu held.
+func (w *Watcher) drop(s *subscriber) {
+	delete(w.subs, s)
+	close(s.ch)
+}
+
+func (w *Watcher) token(seq uint64) string {
+	return fmt.Sprintf("%s.%d", w.epoch, seq)
+}
+
+func (w *Watcher) parse(token string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(token, ".")
+	if !ok || epoch != w.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n > w.seq {
+		return 0, false
+	}
+	return n, true
+}
+
+func newEpoch() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
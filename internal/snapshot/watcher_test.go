@@ -0,0 +1,167 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db"
+	"github.com/gebhn/auth-service/internal/revoked"
+	"github.com/gebhn/auth-service/internal/store"
+)
+
+type streamMock struct {
+	grpc.ServerStream
+	ctx context.Context
+	res chan *pb.WatchRevocationsResponse
+}
+
+func (s *streamMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *streamMock) Send(res *pb.WatchRevocationsResponse) error {
+	s.res <- res
+	return nil
+}
+
+// watchHelper starts a stream and returns it along with a channel which
+// receives the error WatchRevocations returns.
+func watchHelper(t *testing.T, w *Watcher, resume string) (*streamMock, <-chan error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	s := &streamMock{ctx: ctx, res: make(chan *pb.WatchRevocationsResponse, 16)}
+	req := &pb.WatchRevocationsRequest{}
+	req.SetResumeToken(resume)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- w.WatchRevocations(req, s)
+	}()
+	return s, errs
+}
+
+func recvHelper(t *testing.T, s *streamMock) *pb.WatchRevocationsResponse {
+	t.Helper()
+
+	select {
+	case res := <-s.res:
+		return res
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "timed out waiting for response")
+		return nil
+	}
+}
+
+func TestWatchRevocations_Snapshot(t *testing.T) {
+	clearTables(t)
+	revokeHelper(t, "jti1")
+
+	w := NewWatcher(NewGenerator(testStore, testKey, 3), 8)
+	s, _ := watchHelper(t, w, "")
+
+	res := recvHelper(t, s)
+	assert.Equal(t, pb.WatchRevocationsResponse_Snapshot_case, res.WhichEvent())
+	assert.Len(t, res.GetSnapshot().GetTokens(), 1)
+	assert.NotEmpty(t, res.GetResumeToken())
+
+	w.Observe(context.Background(), revoked.Event{
+		Jti:       "jti2",
+		Kind:      pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	w.Observe(context.Background(), revoked.Event{UserID: "1", NotBefore: time.Now()})
+
+	tok := recvHelper(t, s)
+	assert.Equal(t, "jti2", tok.GetToken().GetJti())
+	assert.Equal(t, pb.TokenKind_TOKEN_KIND_REFRESH, tok.GetToken().GetTokenKind())
+	assert.NotEqual(t, res.GetResumeToken(), tok.GetResumeToken())
+
+	cut := recvHelper(t, s)
+	assert.Equal(t, "1", cut.GetCutoff().GetUserId())
+	assert.True(t, cut.GetCutoff().HasNotBefore())
+}
+
+func TestWatchRevocations_Resume(t *testing.T) {
+	clearTables(t)
+
+	w := NewWatcher(NewGenerator(testStore, testKey, 3), 8)
+	w.Observe(context.Background(), revoked.Event{Jti: "jti1"})
+	w.Observe(context.Background(), revoked.Event{Jti: "jti2"})
+	w.Observe(context.Background(), revoked.Event{Jti: "jti3"})
+
+	s, _ := watchHelper(t, w, w.token(1))
+
+	res := recvHelper(t, s)
+	assert.Equal(t, pb.WatchRevocationsResponse_Resumed_case, res.WhichEvent())
+	assert.Equal(t, w.token(1), res.GetResumeToken())
+	assert.Equal(t, "jti2", recvHelper(t, s).GetToken().GetJti())
+	assert.Equal(t, "jti3", recvHelper(t, s).GetToken().GetJti())
+
+	t.Run("Current", func(t *testing.T) {
+		s, _ := watchHelper(t, w, w.token(3))
+
+		res := recvHelper(t, s)
+		assert.Equal(t, pb.WatchRevocationsResponse_Resumed_case, res.WhichEvent())
+		assert.Empty(t, s.res)
+	})
+	t.Run("Too Old", func(t *testing.T) {
+		small := NewWatcher(NewGenerator(testStore, testKey, 3), 1)
+		small.Observe(context.Background(), revoked.Event{Jti: "jti1"})
+		small.Observe(context.Background(), revoked.Event{Jti: "jti2"})
+
+		s, _ := watchHelper(t, small, small.token(0))
+		assert.Equal(t, pb.WatchRevocationsResponse_Snapshot_case, recvHelper(t, s).WhichEvent())
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		for _, token := range []string{"garbage", "other.1", w.token(10)} {
+			s, _ := watchHelper(t, w, token)
+			assert.Equal(t, pb.WatchRevocationsResponse_Snapshot_case, recvHelper(t, s).WhichEvent())
+		}
+	})
+}
+
+func TestWatchRevocations_Resync(t *testing.T) {
+	clearTables(t)
+
+	w := NewWatcher(NewGenerator(testStore, testKey, 3), 8)
+	s, errs := watchHelper(t, w, "")
+	token := recvHelper(t, s).GetResumeToken()
+
+	err := w.Resync(context.Background())
+	assert.NoError(t, err)
+
+	select {
+	case err := <-errs:
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for stream to close")
+	}
+
+	s, _ = watchHelper(t, w, token)
+	assert.Equal(t, pb.WatchRevocationsResponse_Snapshot_case, recvHelper(t, s).WhichEvent())
+}
+
+func TestWatchRevocations_Fail(t *testing.T) {
+	c := db.NewLibsqlConn("file::memory:", "")
+	w := NewWatcher(NewGenerator(store.NewSqlStore(c), testKey, 3), 8)
+
+	_, errs := watchHelper(t, w, "")
+
+	select {
+	case err := <-errs:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timed out waiting for stream to close")
+	}
+}
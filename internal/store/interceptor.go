@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Interceptor derives a WithReadYourWrites context for every request, so that
+// the reads of a request made through a replica Store observe its own writes.
+type Interceptor struct{}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(WithReadYourWrites(ctx), req)
+	}
+}
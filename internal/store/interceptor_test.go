@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func TestInterceptor_ReadYourWrites(t *testing.T) {
+	r, _ := newReplicaHelper(t, "replica_interceptor")
+	user := insertUserHelper(t, r)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		if _, err := r.UpdateUser(ctx, sqlc.UpdateUserParams{UserID: user.UserID, Username: "username2"}); err != nil {
+			return nil, err
+		}
+		return r.GetUserByID(ctx, user.UserID)
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Register_FullMethodName}
+
+	res, err := NewInterceptor().Unary()(context.Background(), &pb.RegisterRequest{}, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "username2", res.(*sqlc.User).Username)
+
+	// Without it the read goes to the replica, which never saw the write.
+	_, err = handler(context.Background(), &pb.RegisterRequest{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,783 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gebhn/auth-service/api/pb"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+// tokenKinds and revocationKinds mirror the check constraints on tokens.kind
+// and revocations.kind.
+var (
+	tokenKinds = []string{
+		pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		pb.TokenKind_TOKEN_KIND_PASSWORD_RESET.String(),
+		pb.TokenKind_TOKEN_KIND_EMAIL_VERIFICATION.String(),
+	}
+	revocationKinds = []string{
+		pb.TokenKind_TOKEN_KIND_REFRESH.String(),
+		pb.TokenKind_TOKEN_KIND_ACCESS.String(),
+		pb.TokenKind_TOKEN_KIND_PASSWORD_RESET.String(),
+		pb.TokenKind_TOKEN_KIND_EMAIL_VERIFICATION.String(),
+		pb.TokenKind_TOKEN_KIND_PASSWORD_CHANGE.String(),
+	}
+)
+
+type memoryData struct {
+	users       map[string]sqlc.User
+	tokens      map[string]sqlc.Token
+	revocations map[string]sqlc.Revocation
+	jobRuns     map[string]sqlc.JobRun
+	// passwordHistory holds the entries of each user, oldest first.
+	passwordHistory map[string][]sqlc.PasswordHistory
+	historyID       int64
+}
+
+func (d *memoryData) clone() *memoryData {
+	history := make(map[string][]sqlc.PasswordHistory, len(d.passwordHistory))
+	for userID, entries := range d.passwordHistory {
+		history[userID] = slices.Clone(entries)
+	}
+	return &memoryData{
+		users:           maps.Clone(d.users),
+		tokens:          maps.Clone(d.tokens),
+		revocations:     maps.Clone(d.revocations),
+		jobRuns:         maps.Clone(d.jobRuns),
+		passwordHistory: history,
+		historyID:       d.historyID,
+	}
+}
+
+// memoryStore keeps every table in maps and enforces the same constraints as
+// the schema. Rows are stored and returned by value so that callers can never
+// modify them in place.
+type memoryStore struct {
+	mu   *sync.RWMutex
+	data *memoryData
+}
+
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		mu: &sync.RWMutex{},
+		data: &memoryData{
+			users:       map[string]sqlc.User{},
+			tokens:      map[string]sqlc.Token{},
+			revocations: map[string]sqlc.Revocation{},
+			jobRuns:     map[string]sqlc.JobRun{},
+
+			passwordHistory: map[string][]sqlc.PasswordHistory{},
+		},
+	}
+}
+
+// ExecTx runs fn against a copy of the data which replaces the original only
+// when fn succeeds. Transactions are serialized, so fn must only use the
+// Store it is given, and never fail with a transient error. Isolation levels
+// are ignored.
+func (s *memoryStore) ExecTx(ctx context.Context, fn func(store Store) error, opts ...TxOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &memoryStore{
+		mu:   &sync.RWMutex{},
+		data: s.data.clone(),
+	}
+	var txStore Store = tx
+	if newTxOptions(opts).ReadOnly {
+		txStore = readOnlyStore{tx}
+	}
+	if err := fn(txStore); err != nil {
+		return err
+	}
+	s.data = tx.data
+	return nil
+}
+
+func (s *memoryStore) CreateUser(ctx context.Context, p sqlc.CreateUserParams) error {
+	if p.UserID == "" || p.Username == "" || p.Email == "" || p.PasswordHash == "" {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[p.UserID]; ok {
+		return fmt.Errorf("%w: users.user_id", ErrConflict)
+	}
+	if err := s.checkUnique(p.UserID, p.Username, p.Email); err != nil {
+		return err
+	}
+	now := time.Now()
+	s.data.users[p.UserID] = sqlc.User{
+		UserID:            p.UserID,
+		Username:          p.Username,
+		Email:             p.Email,
+		PasswordHash:      p.PasswordHash,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Version:           1,
+		PasswordChangedAt: &now,
+	}
+	return nil
+}
+
+func (s *memoryStore) UpdateUser(ctx context.Context, p sqlc.UpdateUserParams) (int64, error) {
+	invalidUsername := p.Username == "" || p.Username == nil
+	invalidEmail := p.Email == "" || p.Email == nil
+	invalidPass := p.PasswordHash == "" || p.PasswordHash == nil
+
+	if p.UserID == "" || p.ExpectedVersion < 0 || (invalidUsername && invalidEmail && invalidPass) {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.data.users[p.UserID]
+	if !ok {
+		return 0, errNoRows
+	}
+	if p.ExpectedVersion != 0 && p.ExpectedVersion != u.Version {
+		return 0, ErrVersionConflict
+	}
+	if v, _ := p.Username.(string); v != "" {
+		u.Username = v
+	}
+	if v, _ := p.Email.(string); v != "" {
+		u.Email = v
+	}
+	if v, _ := p.PasswordHash.(string); v != "" {
+		now := time.Now()
+		u.PasswordHash = v
+		u.PasswordChangedAt = &now
+		u.MustChangePassword = false
+	}
+	if err := s.checkUnique(u.UserID, u.Username, u.Email); err != nil {
+		return 0, err
+	}
+	u.Version++
+	u.UpdatedAt = time.Now()
+	s.data.users[p.UserID] = u
+	return u.Version, nil
+}
+
+func (s *memoryStore) GetUserByID(ctx context.Context, userID string) (*sqlc.User, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.data.users[userID]
+	if !ok {
+		return nil, errNoRows
+	}
+	return &u, nil
+}
+
+func (s *memoryStore) GetUserByEmail(ctx context.Context, email string) (*sqlc.User, error) {
+	if email == "" {
+		return nil, ErrInvalidInput
+	}
+	return s.findUser(func(u sqlc.User) bool { return u.Email == email })
+}
+
+func (s *memoryStore) GetUserByUsername(ctx context.Context, username string) (*sqlc.User, error) {
+	if username == "" {
+		return nil, ErrInvalidInput
+	}
+	return s.findUser(func(u sqlc.User) bool { return u.Username == username })
+}
+
+func (s *memoryStore) SetUserNotBefore(ctx context.Context, p sqlc.SetUserNotBeforeParams) (int64, error) {
+	if p.UserID == "" || p.TokensNotBefore == nil {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.data.users[p.UserID]
+	if !ok {
+		return 0, nil
+	}
+	t := *p.TokensNotBefore
+	u.TokensNotBefore = &t
+	u.UpdatedAt = time.Now()
+	s.data.users[p.UserID] = u
+	return 1, nil
+}
+
+func (s *memoryStore) SetMustChangePassword(ctx context.Context, p sqlc.SetMustChangePasswordParams) (int64, error) {
+	if p.UserID == "" {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.data.users[p.UserID]
+	if !ok {
+		return 0, nil
+	}
+	u.MustChangePassword = p.MustChangePassword
+	u.UpdatedAt = time.Now()
+	s.data.users[p.UserID] = u
+	return 1, nil
+}
+
+func (s *memoryStore) GetUserNotBefore(ctx context.Context, userID string) (*time.Time, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.data.users[userID]
+	if !ok {
+		return nil, errNoRows
+	}
+	return u.TokensNotBefore, nil
+}
+
+func (s *memoryStore) GetUserCutoffs(ctx context.Context, since *time.Time) ([]*sqlc.GetUserCutoffsRow, error) {
+	if since == nil {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := []*sqlc.GetUserCutoffsRow{}
+	for _, id := range slices.Sorted(maps.Keys(s.data.users)) {
+		u := s.data.users[id]
+		if u.TokensNotBefore != nil && u.TokensNotBefore.After(*since) {
+			rows = append(rows, &sqlc.GetUserCutoffsRow{
+				UserID:          u.UserID,
+				TokensNotBefore: u.TokensNotBefore,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (s *memoryStore) CreateToken(ctx context.Context, p sqlc.CreateTokenParams) error {
+	if p.Jti == "" || p.UserID == "" || p.Kind == "" || p.TokenHash == "" {
+		return ErrInvalidInput
+	}
+	if p.IssuedAt.After(time.Now()) {
+		return ErrInvalidInput
+	}
+	if p.ExpiresAt.Before(time.Now()) {
+		return ErrInvalidInput
+	}
+	if !slices.Contains(tokenKinds, p.Kind) {
+		return fmt.Errorf("%w: tokens.kind", ErrInvalidInput)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.tokens[p.Jti]; ok {
+		return fmt.Errorf("%w: tokens.jti", ErrConflict)
+	}
+	if _, ok := s.data.users[p.UserID]; !ok {
+		return fmt.Errorf("%w: tokens.user_id", ErrNotFound)
+	}
+	s.data.tokens[p.Jti] = sqlc.Token{
+		Jti:          p.Jti,
+		UserID:       p.UserID,
+		Kind:         p.Kind,
+		TokenHash:    p.TokenHash,
+		IssuedAt:     p.IssuedAt,
+		ExpiresAt:    p.ExpiresAt,
+		CreatedAt:    time.Now(),
+		ClientName:   clonePtr(p.ClientName),
+		IpAddress:    clonePtr(p.IpAddress),
+		UserAgent:    clonePtr(p.UserAgent),
+		RememberMe:   p.RememberMe,
+		MaxExpiresAt: clonePtr(p.MaxExpiresAt),
+		FamilyID:     clonePtr(p.FamilyID),
+	}
+	return nil
+}
+
+func (s *memoryStore) GetTokenByJTI(ctx context.Context, jti string) (*sqlc.Token, error) {
+	if jti == "" {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.data.tokens[jti]
+	if !ok || !t.ExpiresAt.After(time.Now()) {
+		return nil, errNoRows
+	}
+	return &t, nil
+}
+
+func (s *memoryStore) GetTokensByFamily(ctx context.Context, familyID *string) ([]*sqlc.Token, error) {
+	if familyID == nil || *familyID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	tokens := []*sqlc.Token{}
+	for _, t := range s.data.tokens {
+		if t.FamilyID != nil && *t.FamilyID == *familyID && t.ExpiresAt.After(now) {
+			tokens = append(tokens, &t)
+		}
+	}
+	slices.SortFunc(tokens, func(a, b *sqlc.Token) int {
+		return b.IssuedAt.Compare(a.IssuedAt)
+	})
+	return tokens, nil
+}
+
+func (s *memoryStore) GetTokensForUser(ctx context.Context, userID string) ([]*sqlc.Token, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	tokens := []*sqlc.Token{}
+	for _, t := range s.data.tokens {
+		if t.UserID == userID && t.ExpiresAt.After(now) {
+			tokens = append(tokens, &t)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errNoRows
+	}
+	slices.SortFunc(tokens, func(a, b *sqlc.Token) int {
+		return b.IssuedAt.Compare(a.IssuedAt)
+	})
+	return tokens, nil
+}
+
+func (s *memoryStore) TouchToken(ctx context.Context, p sqlc.TouchTokenParams) (int64, error) {
+	if p.Jti == "" || p.LastUsedAt == nil {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.data.tokens[p.Jti]
+	if !ok {
+		return 0, nil
+	}
+	lastUsedAt := *p.LastUsedAt
+	t.LastUsedAt = &lastUsedAt
+	s.data.tokens[p.Jti] = t
+	return 1, nil
+}
+
+func (s *memoryStore) ExtendToken(ctx context.Context, p sqlc.ExtendTokenParams) (int64, error) {
+	if p.Jti == "" || p.LastUsedAt == nil || !p.ExpiresAt.After(*p.LastUsedAt) {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.data.tokens[p.Jti]
+	if !ok || t.RevokedAt != nil || !t.ExpiresAt.After(time.Now()) {
+		return 0, nil
+	}
+	lastUsedAt := *p.LastUsedAt
+	t.ExpiresAt = p.ExpiresAt
+	t.LastUsedAt = &lastUsedAt
+	s.data.tokens[p.Jti] = t
+	return 1, nil
+}
+
+func (s *memoryStore) ListSessions(ctx context.Context, p sqlc.ListSessionsParams) ([]*sqlc.Token, error) {
+	if p.UserID == "" || p.PageSize <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if p.BeforeIssuedAt.IsZero() {
+		p.BeforeIssuedAt = firstPage
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	tokens := []*sqlc.Token{}
+	for _, t := range s.data.tokens {
+		if t.UserID != p.UserID || t.Kind != pb.TokenKind_TOKEN_KIND_REFRESH.String() {
+			continue
+		}
+		if t.RevokedAt != nil || !t.ExpiresAt.After(now) {
+			continue
+		}
+		if c := t.IssuedAt.Compare(p.BeforeIssuedAt); c > 0 || (c == 0 && t.Jti >= p.BeforeJti) {
+			continue
+		}
+		tokens = append(tokens, &t)
+	}
+	slices.SortFunc(tokens, func(a, b *sqlc.Token) int {
+		return cmp.Or(b.IssuedAt.Compare(a.IssuedAt), cmp.Compare(b.Jti, a.Jti))
+	})
+	return tokens[:min(int64(len(tokens)), p.PageSize)], nil
+}
+
+func (s *memoryStore) RevokeToken(ctx context.Context, jti string) (int64, error) {
+	if jti == "" {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.data.tokens[jti]
+	if !ok {
+		return 0, nil
+	}
+	if t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+		s.data.tokens[jti] = t
+	}
+	return 1, nil
+}
+
+func (s *memoryStore) DeleteExpiredTokens(ctx context.Context, p sqlc.DeleteExpiredTokensParams) (int64, error) {
+	if p.Before.IsZero() || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.tokenBatch(p.BatchSize, func(t sqlc.Token) (time.Time, bool) {
+		return t.ExpiresAt, t.ExpiresAt.Before(p.Before)
+	})
+	for _, t := range batch {
+		delete(s.data.tokens, t.Jti)
+	}
+	return int64(len(batch)), nil
+}
+
+func (s *memoryStore) ArchiveRevokedTokens(ctx context.Context, p sqlc.ArchiveRevokedTokensParams) (int64, error) {
+	if p.Before == nil || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, t := range s.tokenBatch(p.BatchSize, revokedBefore(*p.Before)) {
+		if _, ok := s.data.revocations[t.Jti]; ok {
+			continue
+		}
+		s.data.revocations[t.Jti] = sqlc.Revocation{
+			Jti:       t.Jti,
+			Kind:      t.Kind,
+			RevokedAt: time.Now(),
+			ExpiresAt: t.ExpiresAt,
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *memoryStore) DeleteRevokedTokens(ctx context.Context, p sqlc.DeleteRevokedTokensParams) (int64, error) {
+	if p.Before == nil || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.tokenBatch(p.BatchSize, revokedBefore(*p.Before))
+	for _, t := range batch {
+		delete(s.data.tokens, t.Jti)
+	}
+	return int64(len(batch)), nil
+}
+
+func (s *memoryStore) CreateRevocation(ctx context.Context, p sqlc.CreateRevocationParams) error {
+	if p.Jti == "" || p.Kind == "" {
+		return ErrInvalidInput
+	}
+	if p.ExpiresAt.Before(time.Now()) {
+		return ErrInvalidInput
+	}
+	if !slices.Contains(revocationKinds, p.Kind) {
+		return fmt.Errorf("%w: revocations.kind", ErrInvalidInput)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.revocations[p.Jti]; ok {
+		return nil
+	}
+	s.data.revocations[p.Jti] = sqlc.Revocation{
+		Jti:       p.Jti,
+		Kind:      p.Kind,
+		RevokedAt: time.Now(),
+		ExpiresAt: p.ExpiresAt,
+	}
+	return nil
+}
+
+func (s *memoryStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	if t, ok := s.data.tokens[jti]; ok && t.RevokedAt != nil && t.ExpiresAt.After(now) {
+		return true, nil
+	}
+	r, ok := s.data.revocations[jti]
+	return ok && r.ExpiresAt.After(now), nil
+}
+
+func (s *memoryStore) DeleteExpiredRevocations(ctx context.Context, p sqlc.DeleteExpiredRevocationsParams) (int64, error) {
+	if p.Before.IsZero() || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := []sqlc.Revocation{}
+	for _, r := range s.data.revocations {
+		if r.ExpiresAt.Before(p.Before) {
+			batch = append(batch, r)
+		}
+	}
+	slices.SortFunc(batch, func(a, b sqlc.Revocation) int {
+		return cmp.Or(a.ExpiresAt.Compare(b.ExpiresAt), cmp.Compare(a.Jti, b.Jti))
+	})
+	batch = batch[:min(int64(len(batch)), p.BatchSize)]
+	for _, r := range batch {
+		delete(s.data.revocations, r.Jti)
+	}
+	return int64(len(batch)), nil
+}
+
+func (s *memoryStore) GetRevokedTokens(ctx context.Context) ([]*sqlc.GetRevokedTokensRow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	rows := []*sqlc.GetRevokedTokensRow{}
+	for _, t := range s.data.tokens {
+		if t.RevokedAt != nil && t.ExpiresAt.After(now) {
+			rows = append(rows, &sqlc.GetRevokedTokensRow{Jti: t.Jti, Kind: t.Kind, ExpiresAt: t.ExpiresAt})
+		}
+	}
+	for _, r := range s.data.revocations {
+		if r.ExpiresAt.After(now) {
+			rows = append(rows, &sqlc.GetRevokedTokensRow{Jti: r.Jti, Kind: r.Kind, ExpiresAt: r.ExpiresAt})
+		}
+	}
+	slices.SortFunc(rows, func(a, b *sqlc.GetRevokedTokensRow) int {
+		return cmp.Compare(a.Jti, b.Jti)
+	})
+	return rows, nil
+}
+
+func (s *memoryStore) AcquireJobRun(ctx context.Context, p sqlc.AcquireJobRunParams) (int64, error) {
+	if p.Name == "" || p.Owner == "" || p.Slot.IsZero() {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.data.jobRuns[p.Name]; ok && !r.Slot.Before(p.Slot) {
+		return 0, nil
+	}
+	s.data.jobRuns[p.Name] = sqlc.JobRun{
+		Name:      p.Name,
+		Slot:      p.Slot,
+		Owner:     p.Owner,
+		Status:    "running",
+		StartedAt: time.Now(),
+	}
+	return 1, nil
+}
+
+func (s *memoryStore) FinishJobRun(ctx context.Context, p sqlc.FinishJobRunParams) (int64, error) {
+	if p.Name == "" || p.Owner == "" || p.Slot.IsZero() || p.Status == "" {
+		return 0, ErrInvalidInput
+	}
+	if !slices.Contains([]string{"running", "succeeded", "failed"}, p.Status) {
+		return 0, fmt.Errorf("%w: job_runs.status", ErrInvalidInput)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.data.jobRuns[p.Name]
+	if !ok || !r.Slot.Equal(p.Slot) || r.Owner != p.Owner {
+		return 0, nil
+	}
+	now := time.Now()
+	r.Status = p.Status
+	r.Error = nil
+	if p.Error != nil {
+		e := *p.Error
+		r.Error = &e
+	}
+	r.FinishedAt = &now
+	s.data.jobRuns[p.Name] = r
+	return 1, nil
+}
+
+func (s *memoryStore) GetJobRun(ctx context.Context, name string) (*sqlc.JobRun, error) {
+	if name == "" {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.data.jobRuns[name]
+	if !ok {
+		return nil, errNoRows
+	}
+	return &r, nil
+}
+
+func (s *memoryStore) AddPasswordHistory(ctx context.Context, p sqlc.AddPasswordHistoryParams) error {
+	if p.UserID == "" || p.PasswordHash == "" {
+		return ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[p.UserID]; !ok {
+		return fmt.Errorf("%w: password_history.user_id", ErrNotFound)
+	}
+	s.data.historyID++
+	s.data.passwordHistory[p.UserID] = append(s.data.passwordHistory[p.UserID], sqlc.PasswordHistory{
+		ID:           s.data.historyID,
+		UserID:       p.UserID,
+		PasswordHash: p.PasswordHash,
+		CreatedAt:    time.Now(),
+	})
+	return nil
+}
+
+func (s *memoryStore) GetPasswordHistory(ctx context.Context, p sqlc.GetPasswordHistoryParams) ([]*sqlc.PasswordHistory, error) {
+	if p.UserID == "" || p.Size <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.data.passwordHistory[p.UserID]
+	res := []*sqlc.PasswordHistory{}
+	for i := len(entries) - 1; i >= 0 && len(res) < int(p.Size); i-- {
+		e := entries[i]
+		res = append(res, &e)
+	}
+	return res, nil
+}
+
+func (s *memoryStore) PrunePasswordHistory(ctx context.Context, p sqlc.PrunePasswordHistoryParams) (int64, error) {
+	if p.UserID == "" || p.Keep < 0 {
+		return 0, ErrInvalidInput
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.data.passwordHistory[p.UserID]
+	n := max(len(entries)-int(p.Keep), 0)
+	s.data.passwordHistory[p.UserID] = slices.Clone(entries[n:])
+	return int64(n), nil
+}
+
+func (s *memoryStore) findUser(match func(u sqlc.User) bool) (*sqlc.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.data.users {
+		if match(u) {
+			return &u, nil
+		}
+	}
+	return nil, errNoRows
+}
+
+// tokenBatch returns at most size tokens matched by key, ordered the same way
+// as the queries do. It must be called with mu held.
+func (s *memoryStore) tokenBatch(size int64, key func(t sqlc.Token) (time.Time, bool)) []sqlc.Token {
+	batch := []sqlc.Token{}
+	for _, t := range s.data.tokens {
+		if _, ok := key(t); ok {
+			batch = append(batch, t)
+		}
+	}
+	slices.SortFunc(batch, func(a, b sqlc.Token) int {
+		ka, _ := key(a)
+		kb, _ := key(b)
+		return cmp.Or(ka.Compare(kb), cmp.Compare(a.Jti, b.Jti))
+	})
+	return batch[:min(int64(len(batch)), size)]
+}
+
+// revokedBefore leaves out tokens of a family, which are kept until they
+// expire so that a rotated refresh token can still be recognised as reused.
+func revokedBefore(before time.Time) func(t sqlc.Token) (time.Time, bool) {
+	return func(t sqlc.Token) (time.Time, bool) {
+		if t.RevokedAt == nil || t.FamilyID != nil {
+			return time.Time{}, false
+		}
+		return *t.RevokedAt, t.RevokedAt.Before(before)
+	}
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// checkUnique must be called with mu held.
+func (s *memoryStore) checkUnique(userID, username, email string) error {
+	for _, u := range s.data.users {
+		if u.UserID == userID {
+			continue
+		}
+		if u.Username == username {
+			return ErrUsernameTaken
+		}
+		if u.Email == email {
+			return ErrEmailTaken
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+func TestMemoryGetUserByID_Copy(t *testing.T) {
+	s := NewMemoryStore()
+	user := insertUserHelper(t, s)
+
+	res, err := s.GetUserByID(context.Background(), user.UserID)
+	require.NoError(t, err)
+	res.Username = "modified"
+
+	res, err = s.GetUserByID(context.Background(), user.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Username, res.Username)
+}
+
+func TestMemoryExecTx_Nested(t *testing.T) {
+	s := NewMemoryStore()
+
+	err := s.ExecTx(context.Background(), func(tx Store) error {
+		_ = insertUserHelper(t, tx)
+
+		err := tx.ExecTx(context.Background(), func(nested Store) error {
+			_ = insertTokenHelper(t, nested)
+			return fmt.Errorf("nested failure")
+		})
+		assert.Error(t, err)
+
+		_, err = tx.GetTokenByJTI(context.Background(), "jti")
+		assert.Error(t, err)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	_, err = s.GetUserByID(context.Background(), "1")
+	assert.NoError(t, err)
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	s := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			id := fmt.Sprint(i)
+			err := s.ExecTx(context.Background(), func(tx Store) error {
+				return tx.CreateUser(context.Background(), sqlc.CreateUserParams{
+					UserID:       id,
+					Username:     "username" + id,
+					Email:        "username" + id + "@mail.me",
+					PasswordHash: "pass",
+				})
+			})
+			assert.NoError(t, err)
+
+			_, err = s.GetUserByUsername(context.Background(), "username"+id)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	since := time.Now().Add(-time.Hour)
+	rows, err := s.GetUserCutoffs(context.Background(), &since)
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+}
@@ -0,0 +1,257 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/gebhn/auth-service/internal/db/pgsqlc"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+// NewPostgresStore returns a Store backed by the PostgreSQL queries. It shares
+// validation and transaction handling with every other SQL dialect.
+func NewPostgresStore(db *sql.DB) *sqlStore {
+	return newSqlStore(db, postgresDialect)
+}
+
+var postgresDialect = dialect{
+	newQuerier: func(db sqlc.DBTX) sqlc.Querier {
+		return &postgresQuerier{q: pgsqlc.New(db)}
+	},
+	translate: translatePostgresError,
+	transient: transientPostgresError,
+	txOptions: func(o sql.TxOptions) *sql.TxOptions {
+		return &o
+	},
+}
+
+func translatePostgresError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case pgerrcode.UniqueViolation:
+		switch pgErr.ConstraintName {
+		case "users_username_key":
+			return fmt.Errorf("%w: %w", ErrUsernameTaken, err)
+		case "users_email_key":
+			return fmt.Errorf("%w: %w", ErrEmailTaken, err)
+		default:
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		}
+	case pgerrcode.ForeignKeyViolation:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case pgerrcode.CheckViolation:
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	default:
+		return err
+	}
+}
+
+// transientPostgresError reports serialization failures and deadlocks, which
+// PostgreSQL expects the client to retry.
+func transientPostgresError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected
+}
+
+// postgresQuerier adapts the PostgreSQL queries to sqlc.Querier. Both are
+// generated from the same schema, so rows and params only differ by package.
+type postgresQuerier struct {
+	q *pgsqlc.Queries
+}
+
+func (p *postgresQuerier) AcquireJobRun(ctx context.Context, arg sqlc.AcquireJobRunParams) (int64, error) {
+	return p.q.AcquireJobRun(ctx, pgsqlc.AcquireJobRunParams(arg))
+}
+
+func (p *postgresQuerier) AddPasswordHistory(ctx context.Context, arg sqlc.AddPasswordHistoryParams) error {
+	return p.q.AddPasswordHistory(ctx, pgsqlc.AddPasswordHistoryParams(arg))
+}
+
+func (p *postgresQuerier) ArchiveRevokedTokens(ctx context.Context, arg sqlc.ArchiveRevokedTokensParams) (int64, error) {
+	return p.q.ArchiveRevokedTokens(ctx, pgsqlc.ArchiveRevokedTokensParams(arg))
+}
+
+func (p *postgresQuerier) CreateRevocation(ctx context.Context, arg sqlc.CreateRevocationParams) error {
+	return p.q.CreateRevocation(ctx, pgsqlc.CreateRevocationParams(arg))
+}
+
+func (p *postgresQuerier) CreateToken(ctx context.Context, arg sqlc.CreateTokenParams) error {
+	return p.q.CreateToken(ctx, pgsqlc.CreateTokenParams(arg))
+}
+
+func (p *postgresQuerier) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) error {
+	return p.q.CreateUser(ctx, pgsqlc.CreateUserParams(arg))
+}
+
+func (p *postgresQuerier) DeleteExpiredRevocations(ctx context.Context, arg sqlc.DeleteExpiredRevocationsParams) (int64, error) {
+	return p.q.DeleteExpiredRevocations(ctx, pgsqlc.DeleteExpiredRevocationsParams(arg))
+}
+
+func (p *postgresQuerier) DeleteExpiredTokens(ctx context.Context, arg sqlc.DeleteExpiredTokensParams) (int64, error) {
+	return p.q.DeleteExpiredTokens(ctx, pgsqlc.DeleteExpiredTokensParams(arg))
+}
+
+func (p *postgresQuerier) DeleteRevokedTokens(ctx context.Context, arg sqlc.DeleteRevokedTokensParams) (int64, error) {
+	return p.q.DeleteRevokedTokens(ctx, pgsqlc.DeleteRevokedTokensParams(arg))
+}
+
+func (p *postgresQuerier) ExtendToken(ctx context.Context, arg sqlc.ExtendTokenParams) (int64, error) {
+	return p.q.ExtendToken(ctx, pgsqlc.ExtendTokenParams(arg))
+}
+
+func (p *postgresQuerier) FinishJobRun(ctx context.Context, arg sqlc.FinishJobRunParams) (int64, error) {
+	return p.q.FinishJobRun(ctx, pgsqlc.FinishJobRunParams(arg))
+}
+
+func (p *postgresQuerier) GetJobRun(ctx context.Context, name string) (*sqlc.JobRun, error) {
+	r, err := p.q.GetJobRun(ctx, name)
+	return (*sqlc.JobRun)(r), err
+}
+
+func (p *postgresQuerier) GetPasswordHistory(ctx context.Context, arg sqlc.GetPasswordHistoryParams) ([]*sqlc.PasswordHistory, error) {
+	rows, err := p.q.GetPasswordHistory(ctx, pgsqlc.GetPasswordHistoryParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*sqlc.PasswordHistory, len(rows))
+	for i, r := range rows {
+		res[i] = (*sqlc.PasswordHistory)(r)
+	}
+	return res, nil
+}
+
+func (p *postgresQuerier) GetRevokedTokens(ctx context.Context) ([]*sqlc.GetRevokedTokensRow, error) {
+	rows, err := p.q.GetRevokedTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*sqlc.GetRevokedTokensRow, len(rows))
+	for i, r := range rows {
+		res[i] = (*sqlc.GetRevokedTokensRow)(r)
+	}
+	return res, nil
+}
+
+func (p *postgresQuerier) GetTokenByJTI(ctx context.Context, jti string) (*sqlc.Token, error) {
+	t, err := p.q.GetTokenByJTI(ctx, jti)
+	return (*sqlc.Token)(t), err
+}
+
+func (p *postgresQuerier) GetTokensByFamily(ctx context.Context, familyID *string) ([]*sqlc.Token, error) {
+	tokens, err := p.q.GetTokensByFamily(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*sqlc.Token, len(tokens))
+	for i, t := range tokens {
+		res[i] = (*sqlc.Token)(t)
+	}
+	return res, nil
+}
+
+func (p *postgresQuerier) GetTokensForUser(ctx context.Context, userID string) ([]*sqlc.Token, error) {
+	tokens, err := p.q.GetTokensForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*sqlc.Token, len(tokens))
+	for i, t := range tokens {
+		res[i] = (*sqlc.Token)(t)
+	}
+	return res, nil
+}
+
+func (p *postgresQuerier) GetUserByEmail(ctx context.Context, email string) (*sqlc.User, error) {
+	u, err := p.q.GetUserByEmail(ctx, email)
+	return (*sqlc.User)(u), err
+}
+
+func (p *postgresQuerier) GetUserByID(ctx context.Context, userID string) (*sqlc.User, error) {
+	u, err := p.q.GetUserByID(ctx, userID)
+	return (*sqlc.User)(u), err
+}
+
+func (p *postgresQuerier) GetUserByUsername(ctx context.Context, username string) (*sqlc.User, error) {
+	u, err := p.q.GetUserByUsername(ctx, username)
+	return (*sqlc.User)(u), err
+}
+
+func (p *postgresQuerier) GetUserCutoffs(ctx context.Context, tokensNotBefore *time.Time) ([]*sqlc.GetUserCutoffsRow, error) {
+	rows, err := p.q.GetUserCutoffs(ctx, tokensNotBefore)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*sqlc.GetUserCutoffsRow, len(rows))
+	for i, r := range rows {
+		res[i] = (*sqlc.GetUserCutoffsRow)(r)
+	}
+	return res, nil
+}
+
+func (p *postgresQuerier) GetUserNotBefore(ctx context.Context, userID string) (*time.Time, error) {
+	return p.q.GetUserNotBefore(ctx, userID)
+}
+
+func (p *postgresQuerier) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return p.q.IsRevoked(ctx, jti)
+}
+
+func (p *postgresQuerier) ListSessions(ctx context.Context, arg sqlc.ListSessionsParams) ([]*sqlc.Token, error) {
+	tokens, err := p.q.ListSessions(ctx, pgsqlc.ListSessionsParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*sqlc.Token, len(tokens))
+	for i, t := range tokens {
+		res[i] = (*sqlc.Token)(t)
+	}
+	return res, nil
+}
+
+func (p *postgresQuerier) PrunePasswordHistory(ctx context.Context, arg sqlc.PrunePasswordHistoryParams) (int64, error) {
+	return p.q.PrunePasswordHistory(ctx, pgsqlc.PrunePasswordHistoryParams(arg))
+}
+
+func (p *postgresQuerier) RevokeToken(ctx context.Context, jti string) (int64, error) {
+	return p.q.RevokeToken(ctx, jti)
+}
+
+func (p *postgresQuerier) SetMustChangePassword(ctx context.Context, arg sqlc.SetMustChangePasswordParams) (int64, error) {
+	return p.q.SetMustChangePassword(ctx, pgsqlc.SetMustChangePasswordParams(arg))
+}
+
+func (p *postgresQuerier) SetUserNotBefore(ctx context.Context, arg sqlc.SetUserNotBeforeParams) (int64, error) {
+	return p.q.SetUserNotBefore(ctx, pgsqlc.SetUserNotBeforeParams(arg))
+}
+
+func (p *postgresQuerier) TouchToken(ctx context.Context, arg sqlc.TouchTokenParams) (int64, error) {
+	return p.q.TouchToken(ctx, pgsqlc.TouchTokenParams(arg))
+}
+
+// UpdateUser passes empty strings for missing fields, which the query leaves
+// unchanged just like nil.
+func (p *postgresQuerier) UpdateUser(ctx context.Context, arg sqlc.UpdateUserParams) (int64, error) {
+	username, _ := arg.Username.(string)
+	email, _ := arg.Email.(string)
+	passwordHash, _ := arg.PasswordHash.(string)
+
+	return p.q.UpdateUser(ctx, pgsqlc.UpdateUserParams{
+		Username:        username,
+		Email:           email,
+		PasswordHash:    passwordHash,
+		UserID:          arg.UserID,
+		ExpectedVersion: arg.ExpectedVersion,
+	})
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslatePostgresError(t *testing.T) {
+	cases := []struct {
+		err   *pgconn.PgError
+		want  error
+		label string
+	}{
+		{
+			err:   &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "users_username_key"},
+			want:  ErrUsernameTaken,
+			label: "Username",
+		},
+		{
+			err:   &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "users_email_key"},
+			want:  ErrEmailTaken,
+			label: "Email",
+		},
+		{
+			err:   &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "tokens_pkey"},
+			want:  ErrConflict,
+			label: "Primary Key",
+		},
+		{
+			err:   &pgconn.PgError{Code: pgerrcode.ForeignKeyViolation},
+			want:  ErrNotFound,
+			label: "Foreign Key",
+		},
+		{
+			err:   &pgconn.PgError{Code: pgerrcode.CheckViolation},
+			want:  ErrInvalidInput,
+			label: "Check",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.label, func(t *testing.T) {
+			err := translatePostgresError(tc.err)
+			assert.ErrorIs(t, err, tc.want)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	other := errors.New("other")
+	assert.Equal(t, other, translatePostgresError(other))
+	assert.NoError(t, translatePostgresError(nil))
+}
@@ -0,0 +1,299 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+type stickyKey struct{}
+
+// WithReadYourWrites returns a context in which every read made through a
+// replica Store goes to the primary once a write has been made with it, so
+// that a request always observes its own writes. It is meant to be derived
+// once per request.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stickyKey{}, &atomic.Bool{})
+}
+
+// WithPrimary returns a context in which every read made through a replica
+// Store goes to the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	sticky := &atomic.Bool{}
+	sticky.Store(true)
+	return context.WithValue(ctx, stickyKey{}, sticky)
+}
+
+func markWritten(ctx context.Context) {
+	if sticky, ok := ctx.Value(stickyKey{}).(*atomic.Bool); ok {
+		sticky.Store(true)
+	}
+}
+
+func readsPrimary(ctx context.Context) bool {
+	sticky, ok := ctx.Value(stickyKey{}).(*atomic.Bool)
+	return ok && sticky.Load()
+}
+
+type replica struct {
+	db      *sql.DB
+	s       Store
+	healthy atomic.Bool
+}
+
+// replicaStore sends writes and transactions to the primary and spreads reads
+// across the healthy replicas. A replica which fails a read is taken out of
+// rotation until CheckHealth can ping it again, and the read is retried on the
+// primary.
+type replicaStore struct {
+	primary  Store
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// NewReplicaStore returns a Store for the given database driver which routes
+// reads to replicas, see New. Without replicas every query goes to primary.
+func NewReplicaStore(driver string, primary *sql.DB, replicas ...*sql.DB) *replicaStore {
+	r := &replicaStore{
+		primary:  New(driver, primary),
+		replicas: make([]*replica, len(replicas)),
+	}
+	for i, conn := range replicas {
+		r.replicas[i] = &replica{db: conn, s: New(driver, conn)}
+		r.replicas[i].healthy.Store(true)
+	}
+	return r
+}
+
+// CheckHealth pings every replica, returning those which respond to rotation
+// and removing those which do not.
+func (r *replicaStore) CheckHealth(ctx context.Context) {
+	for i, rep := range r.replicas {
+		err := rep.db.PingContext(ctx)
+		if healthy := err == nil; rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("store: replica %d is healthy again", i)
+			} else {
+				log.Printf("store: replica %d is unhealthy: %v", i, err)
+			}
+		}
+	}
+}
+
+// Run checks the health of every replica each interval until ctx is
+// cancelled.
+func (r *replicaStore) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			r.CheckHealth(ctx)
+		}
+	}
+}
+
+// pick returns the next healthy replica in round robin order, or nil when
+// the read has to go to the primary.
+func (r *replicaStore) pick(ctx context.Context) *replica {
+	if len(r.replicas) == 0 || readsPrimary(ctx) {
+		return nil
+	}
+	n := r.next.Add(1)
+	for i := range len(r.replicas) {
+		rep := r.replicas[(n+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// read runs fn against a replica, falling back to the primary when none is
+// healthy or the replica fails for a reason other than the query itself.
+func read[T any](ctx context.Context, r *replicaStore, fn func(s Store) (T, error)) (T, error) {
+	rep := r.pick(ctx)
+	if rep == nil {
+		return fn(r.primary)
+	}
+	res, err := fn(rep.s)
+	if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidInput) || ctx.Err() != nil {
+		return res, err
+	}
+	if rep.healthy.Swap(false) {
+		log.Printf("store: removing replica after failed read: %v", err)
+	}
+	return fn(r.primary)
+}
+
+func (r *replicaStore) ExecTx(ctx context.Context, fn func(s Store) error, opts ...TxOption) error {
+	markWritten(ctx)
+	return r.primary.ExecTx(ctx, fn, opts...)
+}
+
+func (r *replicaStore) CreateUser(ctx context.Context, p sqlc.CreateUserParams) error {
+	markWritten(ctx)
+	return r.primary.CreateUser(ctx, p)
+}
+
+func (r *replicaStore) UpdateUser(ctx context.Context, p sqlc.UpdateUserParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.UpdateUser(ctx, p)
+}
+
+func (r *replicaStore) GetUserByID(ctx context.Context, userID string) (*sqlc.User, error) {
+	return read(ctx, r, func(s Store) (*sqlc.User, error) {
+		return s.GetUserByID(ctx, userID)
+	})
+}
+
+func (r *replicaStore) GetUserByEmail(ctx context.Context, email string) (*sqlc.User, error) {
+	return read(ctx, r, func(s Store) (*sqlc.User, error) {
+		return s.GetUserByEmail(ctx, email)
+	})
+}
+
+func (r *replicaStore) GetUserByUsername(ctx context.Context, username string) (*sqlc.User, error) {
+	return read(ctx, r, func(s Store) (*sqlc.User, error) {
+		return s.GetUserByUsername(ctx, username)
+	})
+}
+
+func (r *replicaStore) SetUserNotBefore(ctx context.Context, p sqlc.SetUserNotBeforeParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.SetUserNotBefore(ctx, p)
+}
+
+func (r *replicaStore) SetMustChangePassword(ctx context.Context, p sqlc.SetMustChangePasswordParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.SetMustChangePassword(ctx, p)
+}
+
+// GetUserNotBefore always reads from the primary, like every revocation
+// read, a lagging replica would accept tokens after a revoke-all.
+func (r *replicaStore) GetUserNotBefore(ctx context.Context, userID string) (*time.Time, error) {
+	return r.primary.GetUserNotBefore(ctx, userID)
+}
+
+func (r *replicaStore) GetUserCutoffs(ctx context.Context, since *time.Time) ([]*sqlc.GetUserCutoffsRow, error) {
+	return r.primary.GetUserCutoffs(ctx, since)
+}
+
+func (r *replicaStore) CreateToken(ctx context.Context, p sqlc.CreateTokenParams) error {
+	markWritten(ctx)
+	return r.primary.CreateToken(ctx, p)
+}
+
+// GetTokenByJTI reads from the primary, a lagging replica would return a
+// rotated refresh token as not yet revoked.
+func (r *replicaStore) GetTokenByJTI(ctx context.Context, jti string) (*sqlc.Token, error) {
+	return r.primary.GetTokenByJTI(ctx, jti)
+}
+
+// GetTokensByFamily reads from the primary, a replica lagging behind a
+// rotation would leave its newest token out of a family revocation.
+func (r *replicaStore) GetTokensByFamily(ctx context.Context, familyID *string) ([]*sqlc.Token, error) {
+	return r.primary.GetTokensByFamily(ctx, familyID)
+}
+
+func (r *replicaStore) GetTokensForUser(ctx context.Context, userID string) ([]*sqlc.Token, error) {
+	return read(ctx, r, func(s Store) ([]*sqlc.Token, error) {
+		return s.GetTokensForUser(ctx, userID)
+	})
+}
+
+func (r *replicaStore) TouchToken(ctx context.Context, p sqlc.TouchTokenParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.TouchToken(ctx, p)
+}
+
+func (r *replicaStore) ExtendToken(ctx context.Context, p sqlc.ExtendTokenParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.ExtendToken(ctx, p)
+}
+
+func (r *replicaStore) ListSessions(ctx context.Context, p sqlc.ListSessionsParams) ([]*sqlc.Token, error) {
+	return read(ctx, r, func(s Store) ([]*sqlc.Token, error) {
+		return s.ListSessions(ctx, p)
+	})
+}
+
+func (r *replicaStore) RevokeToken(ctx context.Context, jti string) (int64, error) {
+	markWritten(ctx)
+	return r.primary.RevokeToken(ctx, jti)
+}
+
+func (r *replicaStore) DeleteExpiredTokens(ctx context.Context, p sqlc.DeleteExpiredTokensParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.DeleteExpiredTokens(ctx, p)
+}
+
+func (r *replicaStore) ArchiveRevokedTokens(ctx context.Context, p sqlc.ArchiveRevokedTokensParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.ArchiveRevokedTokens(ctx, p)
+}
+
+func (r *replicaStore) DeleteRevokedTokens(ctx context.Context, p sqlc.DeleteRevokedTokensParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.DeleteRevokedTokens(ctx, p)
+}
+
+func (r *replicaStore) CreateRevocation(ctx context.Context, p sqlc.CreateRevocationParams) error {
+	markWritten(ctx)
+	return r.primary.CreateRevocation(ctx, p)
+}
+
+// IsRevoked always reads from the primary, a lagging replica would accept a
+// token which was just revoked.
+func (r *replicaStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return r.primary.IsRevoked(ctx, jti)
+}
+
+func (r *replicaStore) DeleteExpiredRevocations(ctx context.Context, p sqlc.DeleteExpiredRevocationsParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.DeleteExpiredRevocations(ctx, p)
+}
+
+func (r *replicaStore) GetRevokedTokens(ctx context.Context) ([]*sqlc.GetRevokedTokensRow, error) {
+	return r.primary.GetRevokedTokens(ctx)
+}
+
+func (r *replicaStore) AcquireJobRun(ctx context.Context, p sqlc.AcquireJobRunParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.AcquireJobRun(ctx, p)
+}
+
+func (r *replicaStore) FinishJobRun(ctx context.Context, p sqlc.FinishJobRunParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.FinishJobRun(ctx, p)
+}
+
+// GetJobRun always reads from the primary, since the run was most likely
+// just finished by another instance.
+func (r *replicaStore) GetJobRun(ctx context.Context, name string) (*sqlc.JobRun, error) {
+	return r.primary.GetJobRun(ctx, name)
+}
+
+func (r *replicaStore) AddPasswordHistory(ctx context.Context, p sqlc.AddPasswordHistoryParams) error {
+	markWritten(ctx)
+	return r.primary.AddPasswordHistory(ctx, p)
+}
+
+// GetPasswordHistory always reads from the primary, a lagging replica would
+// let a password which was just replaced be reused.
+func (r *replicaStore) GetPasswordHistory(ctx context.Context, p sqlc.GetPasswordHistoryParams) ([]*sqlc.PasswordHistory, error) {
+	return r.primary.GetPasswordHistory(ctx, p)
+}
+
+func (r *replicaStore) PrunePasswordHistory(ctx context.Context, p sqlc.PrunePasswordHistoryParams) (int64, error) {
+	markWritten(ctx)
+	return r.primary.PrunePasswordHistory(ctx, p)
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebhn/auth-service/internal/db"
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+// newReplicaHelper returns a replica Store whose only replica is a separate,
+// empty database which never receives the writes made to the primary.
+func newReplicaHelper(t *testing.T, name string) (*replicaStore, *sql.DB) {
+	t.Helper()
+
+	clearTables(t, testDB)
+
+	conn := db.NewLibsqlConn("file:"+name+"?mode=memory&cache=shared", "")
+	t.Cleanup(func() { conn.Close() })
+	require.NoError(t, db.NewMigrator(conn).Up())
+	clearTables(t, conn)
+
+	return NewReplicaStore(db.DriverLibsql, testDB, conn), conn
+}
+
+func TestReplicaStore_Routing(t *testing.T) {
+	r, _ := newReplicaHelper(t, "replica_routing")
+	user := insertUserHelper(t, r)
+
+	_, err := r.GetUserByID(context.Background(), user.UserID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = r.GetUserByID(WithPrimary(context.Background()), user.UserID)
+	assert.NoError(t, err)
+
+	// Tokens are always read from the primary, reuse detection must see a
+	// rotation as soon as it is made.
+	token := insertTokenHelper(t, r)
+	_, err = r.GetTokenByJTI(context.Background(), token.Jti)
+	assert.NoError(t, err)
+}
+
+func TestReplicaStore_ReadYourWrites(t *testing.T) {
+	r, _ := newReplicaHelper(t, "replica_sticky")
+	ctx := WithReadYourWrites(context.Background())
+
+	_, err := r.GetUserByID(ctx, "1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	user := insertUserHelper(t, r)
+	_, err = r.GetUserByID(ctx, user.UserID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = r.UpdateUser(ctx, sqlc.UpdateUserParams{UserID: user.UserID, Username: "username2"})
+	require.NoError(t, err)
+
+	res, err := r.GetUserByID(ctx, user.UserID)
+	assert.NoError(t, err)
+	assert.Equal(t, "username2", res.Username)
+
+	// Other requests keep reading from the replica.
+	_, err = r.GetUserByID(WithReadYourWrites(context.Background()), user.UserID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestReplicaStore_Unhealthy(t *testing.T) {
+	r, conn := newReplicaHelper(t, "replica_unhealthy")
+	user := insertUserHelper(t, r)
+
+	require.NoError(t, conn.Close())
+
+	_, err := r.GetUserByID(context.Background(), user.UserID)
+	assert.NoError(t, err)
+	assert.False(t, r.replicas[0].healthy.Load())
+
+	r.CheckHealth(context.Background())
+	assert.False(t, r.replicas[0].healthy.Load())
+	assert.Nil(t, r.pick(context.Background()))
+}
+
+func TestReplicaStore_Recovered(t *testing.T) {
+	r, _ := newReplicaHelper(t, "replica_recovered")
+	r.replicas[0].healthy.Store(false)
+
+	r.CheckHealth(context.Background())
+	assert.True(t, r.replicas[0].healthy.Load())
+	assert.Same(t, r.replicas[0], r.pick(context.Background()))
+}
+
+func TestReplicaStore_Revocations(t *testing.T) {
+	r, _ := newReplicaHelper(t, "replica_revocations")
+	user := insertUserHelper(t, r)
+	token := insertTokenHelper(t, r)
+
+	_, err := r.RevokeToken(context.Background(), token.Jti)
+	require.NoError(t, err)
+	now := time.Now()
+	_, err = r.SetUserNotBefore(context.Background(), sqlc.SetUserNotBeforeParams{UserID: user.UserID, TokensNotBefore: &now})
+	require.NoError(t, err)
+
+	// The replica never sees the writes, every revocation read must still
+	// observe them.
+	revoked, err := r.IsRevoked(context.Background(), token.Jti)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	rows, err := r.GetRevokedTokens(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	notBefore, err := r.GetUserNotBefore(context.Background(), user.UserID)
+	assert.NoError(t, err)
+	assert.NotNil(t, notBefore)
+
+	since := now.Add(-time.Hour)
+	cutoffs, err := r.GetUserCutoffs(context.Background(), &since)
+	assert.NoError(t, err)
+	assert.Len(t, cutoffs, 1)
+}
@@ -3,30 +3,101 @@ package store
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gebhn/auth-service/internal/db/sqlc"
 )
 
+// dialect binds the queries generated for a specific SQL dialect to a
+// connection or transaction, and translates its driver errors into the
+// errors declared by this package.
+type dialect struct {
+	newQuerier func(db sqlc.DBTX) sqlc.Querier
+	translate  func(err error) error
+	// transient reports whether a transaction failing with err can succeed
+	// when attempted again.
+	transient func(err error) bool
+	// txOptions returns the options handed to BeginTx for a transaction
+	// started with o.
+	txOptions func(o sql.TxOptions) *sql.TxOptions
+}
+
+var sqliteDialect = dialect{
+	newQuerier: func(db sqlc.DBTX) sqlc.Querier {
+		return sqlc.New(db)
+	},
+	translate: translateSqliteError,
+	transient: transientSqliteError,
+	// The libsql HTTP transport rejects read only transactions and any
+	// isolation level, and SQLite runs every transaction serializably
+	// anyway. ReadOnly is enforced through readOnlyStore alone.
+	txOptions: func(o sql.TxOptions) *sql.TxOptions {
+		return nil
+	},
+}
+
+// sqlStore validates input before running queries through its dialect.
+// Stores handed to ExecTx callbacks carry the transaction and how many
+// savepoints deep they are.
 type sqlStore struct {
 	db *sql.DB
-	*sqlc.Queries
+	sqlc.Querier
+	dialect dialect
+
+	tx    *sql.Tx
+	depth int
 }
 
 func NewSqlStore(db *sql.DB) *sqlStore {
+	return newSqlStore(db, sqliteDialect)
+}
+
+func newSqlStore(db *sql.DB, d dialect) *sqlStore {
 	return &sqlStore{
 		db:      db,
-		Queries: sqlc.New(db),
+		Querier: d.newQuerier(db),
+		dialect: d,
+	}
+}
+
+func (s *sqlStore) ExecTx(ctx context.Context, fn func(store Store) error, opts ...TxOption) error {
+	if s.tx != nil {
+		return s.execSavepoint(ctx, fn)
+	}
+
+	o := newTxOptions(opts)
+	var err error
+	for attempt := range o.attempts {
+		if attempt > 0 {
+			if err := sleepCtx(ctx, txBackoff(attempt-1)); err != nil {
+				return err
+			}
+		}
+		err = s.execTx(ctx, fn, o)
+		if err == nil || !s.dialect.transient(err) {
+			return err
+		}
 	}
+	return err
 }
 
-func (s *sqlStore) ExecTx(ctx context.Context, fn func(store Store) error) error {
-	tx, err := s.db.BeginTx(ctx, nil)
+func (s *sqlStore) execTx(ctx context.Context, fn func(store Store) error, o txOptions) error {
+	tx, err := s.db.BeginTx(ctx, s.dialect.txOptions(o.TxOptions))
 	if err != nil {
 		return err
 	}
 
-	txStore := s.newTxStore(tx)
+	var txStore Store = s.newTxStore(tx)
+	if o.ReadOnly {
+		txStore = readOnlyStore{txStore}
+	}
 
 	if err := fn(txStore); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -38,43 +109,110 @@ func (s *sqlStore) ExecTx(ctx context.Context, fn func(store Store) error) error
 	return tx.Commit()
 }
 
+// execSavepoint runs fn inside the transaction s already belongs to. It is
+// never retried on its own, a transient error fails the outer transaction
+// which retries as a whole.
+func (s *sqlStore) execSavepoint(ctx context.Context, fn func(store Store) error) error {
+	sp := fmt.Sprintf("sp_%d", s.depth+1)
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT "+sp); err != nil {
+		return err
+	}
+
+	nested := *s
+	nested.depth++
+
+	if err := fn(&nested); err != nil {
+		if _, rbErr := s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp); rbErr != nil {
+			return rbErr
+		}
+		if _, relErr := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+sp); relErr != nil {
+			return relErr
+		}
+		return err
+	}
+
+	_, err := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+sp)
+	return err
+}
+
 func (s *sqlStore) CreateUser(ctx context.Context, p sqlc.CreateUserParams) error {
 	if p.UserID == "" || p.Username == "" || p.Email == "" || p.PasswordHash == "" {
 		return ErrInvalidInput
 	}
-	return s.Queries.CreateUser(ctx, p)
+	return s.translate(s.Querier.CreateUser(ctx, p))
 }
 
-func (s *sqlStore) UpdateUser(ctx context.Context, p sqlc.UpdateUserParams) error {
+// UpdateUser returns the new version of the user. An ExpectedVersion of 0
+// updates the user regardless of its current version.
+func (s *sqlStore) UpdateUser(ctx context.Context, p sqlc.UpdateUserParams) (int64, error) {
 	invalidUsername := p.Username == "" || p.Username == nil
 	invalidEmail := p.Email == "" || p.Email == nil
 	invalidPass := p.PasswordHash == "" || p.PasswordHash == nil
 
-	if p.UserID == "" || (invalidUsername && invalidEmail && invalidPass) {
-		return ErrInvalidInput
+	if p.UserID == "" || p.ExpectedVersion < 0 || (invalidUsername && invalidEmail && invalidPass) {
+		return 0, ErrInvalidInput
 	}
-	return s.Queries.UpdateUser(ctx, p)
+	version, err := s.Querier.UpdateUser(ctx, p)
+	if errors.Is(err, sql.ErrNoRows) && p.ExpectedVersion != 0 {
+		// No row matched, which is a conflict unless the user does not exist.
+		if _, getErr := s.Querier.GetUserByID(ctx, p.UserID); getErr == nil {
+			return 0, ErrVersionConflict
+		}
+	}
+	return version, s.translate(err)
 }
 
 func (s *sqlStore) GetUserByID(ctx context.Context, userID string) (*sqlc.User, error) {
 	if userID == "" {
 		return nil, ErrInvalidInput
 	}
-	return s.Queries.GetUserByID(ctx, userID)
+	u, err := s.Querier.GetUserByID(ctx, userID)
+	return u, s.translate(err)
 }
 
 func (s *sqlStore) GetUserByEmail(ctx context.Context, email string) (*sqlc.User, error) {
 	if email == "" {
 		return nil, ErrInvalidInput
 	}
-	return s.Queries.GetUserByEmail(ctx, email)
+	u, err := s.Querier.GetUserByEmail(ctx, email)
+	return u, s.translate(err)
 }
 
 func (s *sqlStore) GetUserByUsername(ctx context.Context, username string) (*sqlc.User, error) {
 	if username == "" {
 		return nil, ErrInvalidInput
 	}
-	return s.Queries.GetUserByUsername(ctx, username)
+	u, err := s.Querier.GetUserByUsername(ctx, username)
+	return u, s.translate(err)
+}
+
+func (s *sqlStore) SetUserNotBefore(ctx context.Context, p sqlc.SetUserNotBeforeParams) (int64, error) {
+	if p.UserID == "" || p.TokensNotBefore == nil {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.SetUserNotBefore(ctx, p)
+}
+
+func (s *sqlStore) SetMustChangePassword(ctx context.Context, p sqlc.SetMustChangePasswordParams) (int64, error) {
+	if p.UserID == "" {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.SetMustChangePassword(ctx, p)
+}
+
+func (s *sqlStore) GetUserNotBefore(ctx context.Context, userID string) (*time.Time, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+	t, err := s.Querier.GetUserNotBefore(ctx, userID)
+	return t, s.translate(err)
+}
+
+func (s *sqlStore) GetUserCutoffs(ctx context.Context, since *time.Time) ([]*sqlc.GetUserCutoffsRow, error) {
+	if since == nil {
+		return nil, ErrInvalidInput
+	}
+	return s.Querier.GetUserCutoffs(ctx, since)
 }
 
 func (s *sqlStore) CreateToken(ctx context.Context, p sqlc.CreateTokenParams) error {
@@ -87,33 +225,237 @@ func (s *sqlStore) CreateToken(ctx context.Context, p sqlc.CreateTokenParams) er
 	if p.ExpiresAt.Before(time.Now()) {
 		return ErrInvalidInput
 	}
-	return s.Queries.CreateToken(ctx, p)
+	return s.translate(s.Querier.CreateToken(ctx, p))
 }
 
 func (s *sqlStore) GetTokenByJTI(ctx context.Context, jti string) (*sqlc.Token, error) {
 	if jti == "" {
 		return nil, ErrInvalidInput
 	}
-	return s.Queries.GetTokenByJTI(ctx, jti)
+	t, err := s.Querier.GetTokenByJTI(ctx, jti)
+	return t, s.translate(err)
+}
+
+// GetTokensByFamily returns the live tokens rotated from the same login,
+// newest first.
+func (s *sqlStore) GetTokensByFamily(ctx context.Context, familyID *string) ([]*sqlc.Token, error) {
+	if familyID == nil || *familyID == "" {
+		return nil, ErrInvalidInput
+	}
+	tokens, err := s.Querier.GetTokensByFamily(ctx, familyID)
+	return tokens, s.translate(err)
 }
 
 func (s *sqlStore) GetTokensForUser(ctx context.Context, userID string) ([]*sqlc.Token, error) {
 	if userID == "" {
 		return nil, ErrInvalidInput
 	}
-	tokens, err := s.Queries.GetTokensForUser(ctx, userID)
+	tokens, err := s.Querier.GetTokensForUser(ctx, userID)
 	if err != nil {
-		return nil, err
+		return nil, s.translate(err)
 	}
 	if len(tokens) == 0 {
-		return nil, sql.ErrNoRows
+		return nil, errNoRows
 	}
 	return tokens, nil
 }
 
+func (s *sqlStore) TouchToken(ctx context.Context, p sqlc.TouchTokenParams) (int64, error) {
+	if p.Jti == "" || p.LastUsedAt == nil {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.TouchToken(ctx, p)
+}
+
+// ExtendToken moves the expiry of a live, unrevoked token and records its
+// use, it reports no rows for a token which has already expired.
+func (s *sqlStore) ExtendToken(ctx context.Context, p sqlc.ExtendTokenParams) (int64, error) {
+	if p.Jti == "" || p.LastUsedAt == nil || !p.ExpiresAt.After(*p.LastUsedAt) {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.ExtendToken(ctx, p)
+}
+
+// ListSessions returns a page of the user's live refresh tokens, newest
+// first, starting after the cursor given by BeforeIssuedAt and BeforeJti. A
+// zero BeforeIssuedAt starts from the newest token.
+func (s *sqlStore) ListSessions(ctx context.Context, p sqlc.ListSessionsParams) ([]*sqlc.Token, error) {
+	if p.UserID == "" || p.PageSize <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if p.BeforeIssuedAt.IsZero() {
+		p.BeforeIssuedAt = firstPage
+	}
+	return s.Querier.ListSessions(ctx, p)
+}
+
+func (s *sqlStore) RevokeToken(ctx context.Context, jti string) (int64, error) {
+	if jti == "" {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.RevokeToken(ctx, jti)
+}
+
+func (s *sqlStore) CreateRevocation(ctx context.Context, p sqlc.CreateRevocationParams) error {
+	if p.Jti == "" || p.Kind == "" {
+		return ErrInvalidInput
+	}
+	if p.ExpiresAt.Before(time.Now()) {
+		return ErrInvalidInput
+	}
+	return s.translate(s.Querier.CreateRevocation(ctx, p))
+}
+
+func (s *sqlStore) DeleteExpiredTokens(ctx context.Context, p sqlc.DeleteExpiredTokensParams) (int64, error) {
+	if p.Before.IsZero() || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.DeleteExpiredTokens(ctx, p)
+}
+
+func (s *sqlStore) ArchiveRevokedTokens(ctx context.Context, p sqlc.ArchiveRevokedTokensParams) (int64, error) {
+	if p.Before == nil || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.ArchiveRevokedTokens(ctx, p)
+}
+
+func (s *sqlStore) DeleteRevokedTokens(ctx context.Context, p sqlc.DeleteRevokedTokensParams) (int64, error) {
+	if p.Before == nil || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.DeleteRevokedTokens(ctx, p)
+}
+
+func (s *sqlStore) DeleteExpiredRevocations(ctx context.Context, p sqlc.DeleteExpiredRevocationsParams) (int64, error) {
+	if p.Before.IsZero() || p.BatchSize <= 0 {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.DeleteExpiredRevocations(ctx, p)
+}
+
+func (s *sqlStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, ErrInvalidInput
+	}
+	return s.Querier.IsRevoked(ctx, jti)
+}
+
+func (s *sqlStore) AcquireJobRun(ctx context.Context, p sqlc.AcquireJobRunParams) (int64, error) {
+	if p.Name == "" || p.Owner == "" || p.Slot.IsZero() {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.AcquireJobRun(ctx, p)
+}
+
+func (s *sqlStore) FinishJobRun(ctx context.Context, p sqlc.FinishJobRunParams) (int64, error) {
+	if p.Name == "" || p.Owner == "" || p.Slot.IsZero() || p.Status == "" {
+		return 0, ErrInvalidInput
+	}
+	n, err := s.Querier.FinishJobRun(ctx, p)
+	return n, s.translate(err)
+}
+
+func (s *sqlStore) GetJobRun(ctx context.Context, name string) (*sqlc.JobRun, error) {
+	if name == "" {
+		return nil, ErrInvalidInput
+	}
+	r, err := s.Querier.GetJobRun(ctx, name)
+	return r, s.translate(err)
+}
+
+func (s *sqlStore) AddPasswordHistory(ctx context.Context, p sqlc.AddPasswordHistoryParams) error {
+	if p.UserID == "" || p.PasswordHash == "" {
+		return ErrInvalidInput
+	}
+	return s.translate(s.Querier.AddPasswordHistory(ctx, p))
+}
+
+// GetPasswordHistory returns the last Size password hashes of the user,
+// newest first.
+func (s *sqlStore) GetPasswordHistory(ctx context.Context, p sqlc.GetPasswordHistoryParams) ([]*sqlc.PasswordHistory, error) {
+	if p.UserID == "" || p.Size <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.Querier.GetPasswordHistory(ctx, p)
+}
+
+// PrunePasswordHistory deletes all but the last Keep password hashes of the
+// user.
+func (s *sqlStore) PrunePasswordHistory(ctx context.Context, p sqlc.PrunePasswordHistoryParams) (int64, error) {
+	if p.UserID == "" || p.Keep < 0 {
+		return 0, ErrInvalidInput
+	}
+	return s.Querier.PrunePasswordHistory(ctx, p)
+}
+
+// translate maps sql.ErrNoRows and constraint violations reported by the
+// dialect to the errors declared by this package, keeping the original error
+// wrapped.
+func (s *sqlStore) translate(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return s.dialect.translate(err)
+}
+
 func (s *sqlStore) newTxStore(tx *sql.Tx) *sqlStore {
 	return &sqlStore{
 		db:      s.db,
-		Queries: sqlc.New(tx),
+		Querier: s.dialect.newQuerier(tx),
+		dialect: s.dialect,
+		tx:      tx,
+	}
+}
+
+// translateSqliteError matches on the message rather than the error type,
+// since local databases report a *sqlite.Error while remote libsql databases
+// only return the message.
+func translateSqliteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed: users.username"):
+		return fmt.Errorf("%w: %w", ErrUsernameTaken, err)
+	case strings.Contains(msg, "UNIQUE constraint failed: users.email"):
+		return fmt.Errorf("%w: %w", ErrEmailTaken, err)
+	case strings.Contains(msg, "UNIQUE constraint failed"):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case strings.Contains(msg, "CHECK constraint failed"):
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	default:
+		return err
 	}
 }
+
+// transientLibsqlStatus matches the HTTP statuses of a libsql server which is
+// overloaded or briefly unreachable.
+var transientLibsqlStatus = regexp.MustCompile(`error code (429|502|503|504):`)
+
+// transientSqliteError matches the busy and locked errors reported when
+// another connection holds a conflicting lock on the database, and the
+// failures of the libsql HTTP transport: a closed or expired stream, a
+// network error or an overloaded server.
+func transientSqliteError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	return transientLibsqlStatus.MatchString(msg) ||
+		strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked") ||
+		strings.Contains(msg, "SQLITE_BUSY") ||
+		strings.Contains(msg, "SQLITE_LOCKED")
+}
@@ -2,16 +2,60 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/gebhn/auth-service/internal/db"
 	"github.com/gebhn/auth-service/internal/db/sqlc"
 )
 
-var ErrInvalidInput = errors.New("invalid input")
+var (
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrUsernameTaken = errors.New("username taken")
+	ErrEmailTaken    = errors.New("email taken")
+)
+
+// ErrVersionConflict is returned by UpdateUser when the user was changed since
+// the expected version was read, it matches ErrConflict.
+var ErrVersionConflict = fmt.Errorf("version %w", ErrConflict)
+
+// firstPage is the keyset cursor preceding every row ordered by a timestamp
+// descending.
+var firstPage = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// errNoRows is returned by every Store when a row does not exist, it matches
+// both ErrNotFound and sql.ErrNoRows.
+var errNoRows = fmt.Errorf("%w: %w", ErrNotFound, sql.ErrNoRows)
 
 type Store interface {
 	sqlc.Querier
-	ExecTx(ctx context.Context, fn func(s Store) error) error
+	// ExecTx runs fn in a transaction which is committed when fn returns nil.
+	// A transaction failing with a transient error, such as SQLITE_BUSY or a
+	// serialization failure, is retried from the start, so fn may run more
+	// than once and must not have side effects outside of the Store it is
+	// given. Calling ExecTx on that Store nests through a savepoint which is
+	// rolled back on its own when the nested fn fails.
+	ExecTx(ctx context.Context, fn func(s Store) error, opts ...TxOption) error
 }
 
-var _ Store = (*sqlStore)(nil)
+// New returns the Store for the given database driver, see db.DriverLibsql
+// and db.DriverPostgres.
+func New(driver string, conn *sql.DB) Store {
+	if driver == db.DriverPostgres {
+		return NewPostgresStore(conn)
+	}
+	return NewSqlStore(conn)
+}
+
+var (
+	_ Store = (*sqlStore)(nil)
+	_ Store = (*memoryStore)(nil)
+	_ Store = (*replicaStore)(nil)
+	_ Store = readOnlyStore{}
+
+	_ sqlc.Querier = (*postgresQuerier)(nil)
+)
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gebhn/auth-service/internal/db/sqlc"
+)
+
+var ErrReadOnly = errors.New("read-only transaction")
+
+const (
+	defaultTxAttempts = 5
+	txBackoffBase     = time.Millisecond * 10
+	txBackoffMax      = time.Millisecond * 500
+)
+
+type txOptions struct {
+	sql.TxOptions
+	attempts int
+}
+
+// TxOption configures a transaction started by Store.ExecTx. Options passed
+// to a nested ExecTx are ignored, since it joins the outer transaction.
+type TxOption func(o *txOptions)
+
+// ReadOnly rejects every write made through the transaction with ErrReadOnly.
+// PostgreSQL also starts the transaction read only, SQLite only relies on the
+// Store handed to fn.
+func ReadOnly() TxOption {
+	return func(o *txOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// WithIsolation sets the isolation level of the transaction. SQLite always
+// runs transactions serializably, so the level is not passed to its driver,
+// which may reject it.
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) {
+		o.Isolation = level
+	}
+}
+
+// WithMaxAttempts limits how many times a transaction is attempted when it
+// keeps failing with a transient error, 1 disables retries.
+func WithMaxAttempts(n int) TxOption {
+	return func(o *txOptions) {
+		o.attempts = max(n, 1)
+	}
+}
+
+func newTxOptions(opts []TxOption) txOptions {
+	o := txOptions{attempts: defaultTxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// txBackoff returns how long to wait before the given retry, doubling from
+// txBackoffBase up to txBackoffMax with jitter so that contending
+// transactions do not retry in lockstep.
+func txBackoff(retry int) time.Duration {
+	d := min(txBackoffBase<<min(retry, 16), txBackoffMax)
+	return d/2 + rand.N(d/2+1)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// readOnlyStore is handed to fn by ExecTx when ReadOnly is set. Reads pass
+// through to the transaction while writes fail before reaching it.
+type readOnlyStore struct {
+	Store
+}
+
+func (r readOnlyStore) ExecTx(ctx context.Context, fn func(s Store) error, opts ...TxOption) error {
+	return r.Store.ExecTx(ctx, func(s Store) error {
+		return fn(readOnlyStore{s})
+	}, opts...)
+}
+
+func (r readOnlyStore) CreateUser(ctx context.Context, p sqlc.CreateUserParams) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyStore) UpdateUser(ctx context.Context, p sqlc.UpdateUserParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) SetUserNotBefore(ctx context.Context, p sqlc.SetUserNotBeforeParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) SetMustChangePassword(ctx context.Context, p sqlc.SetMustChangePasswordParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) CreateToken(ctx context.Context, p sqlc.CreateTokenParams) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyStore) RevokeToken(ctx context.Context, jti string) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) CreateRevocation(ctx context.Context, p sqlc.CreateRevocationParams) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyStore) DeleteExpiredTokens(ctx context.Context, p sqlc.DeleteExpiredTokensParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) ArchiveRevokedTokens(ctx context.Context, p sqlc.ArchiveRevokedTokensParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) DeleteRevokedTokens(ctx context.Context, p sqlc.DeleteRevokedTokensParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) DeleteExpiredRevocations(ctx context.Context, p sqlc.DeleteExpiredRevocationsParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) AcquireJobRun(ctx context.Context, p sqlc.AcquireJobRunParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) FinishJobRun(ctx context.Context, p sqlc.FinishJobRunParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) TouchToken(ctx context.Context, p sqlc.TouchTokenParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) ExtendToken(ctx context.Context, p sqlc.ExtendTokenParams) (int64, error) {
+	return 0, ErrReadOnly
+}
+
+func (r readOnlyStore) AddPasswordHistory(ctx context.Context, p sqlc.AddPasswordHistoryParams) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyStore) PrunePasswordHistory(ctx context.Context, p sqlc.PrunePasswordHistoryParams) (int64, error) {
+	return 0, ErrReadOnly
+}
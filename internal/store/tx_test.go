@@ -0,0 +1,28 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTxOptions(t *testing.T) {
+	o := newTxOptions(nil)
+	assert.Equal(t, defaultTxAttempts, o.attempts)
+	assert.False(t, o.ReadOnly)
+	assert.Equal(t, sql.LevelDefault, o.Isolation)
+
+	o = newTxOptions([]TxOption{ReadOnly(), WithIsolation(sql.LevelRepeatableRead), WithMaxAttempts(0)})
+	assert.Equal(t, 1, o.attempts)
+	assert.True(t, o.ReadOnly)
+	assert.Equal(t, sql.LevelRepeatableRead, o.Isolation)
+}
+
+func TestTxBackoff(t *testing.T) {
+	for retry := range 100 {
+		d := txBackoff(retry)
+		assert.LessOrEqual(t, d, txBackoffMax)
+		assert.GreaterOrEqual(t, d, txBackoffBase/2)
+	}
+}
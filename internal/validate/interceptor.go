@@ -0,0 +1,61 @@
+package validate
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/internal/rpcerr"
+)
+
+// Reason is the ErrorInfo reason of a request rejected by the Interceptor.
+const Reason = "INVALID_REQUEST"
+
+// Interceptor rejects requests which break the rules declared on their
+// messages with codes.InvalidArgument, listing every violation in a
+// BadRequest detail.
+type Interceptor struct{}
+
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if m, ok := req.(proto.Message); ok {
+			if err := Validate(m); err != nil {
+				return nil, toStatus(err)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func toStatus(err error) error {
+	verr := &Error{}
+	if !errors.As(err, &verr) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, v := range verr.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+			Reason:      v.Rule,
+		})
+	}
+	st, serr := status.New(codes.InvalidArgument, verr.Error()).WithDetails(
+		&errdetails.ErrorInfo{Reason: Reason, Domain: rpcerr.Domain},
+		br,
+	)
+	if serr != nil {
+		return status.Error(codes.InvalidArgument, verr.Error())
+	}
+	return st.Err()
+}
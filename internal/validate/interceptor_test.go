@@ -0,0 +1,60 @@
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+func callHelper(req any) (bool, error) {
+	called := false
+	handler := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Register_FullMethodName}
+
+	_, err := NewInterceptor().Unary()(context.Background(), req, info, handler)
+	return called, err
+}
+
+func TestInterceptor_Invalid(t *testing.T) {
+	called, err := callHelper(pb.RegisterRequest_builder{
+		Username: proto.String("username"),
+		Password: proto.String("correct horse"),
+	}.Build())
+	assert.False(t, called)
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			violations = br.GetFieldViolations()
+		}
+	}
+	require.Len(t, violations, 1)
+	assert.Equal(t, "email", violations[0].GetField())
+	assert.Equal(t, "required", violations[0].GetReason())
+}
+
+func TestInterceptor_Valid(t *testing.T) {
+	called, err := callHelper(pb.RegisterRequest_builder{
+		Username: proto.String("username"),
+		Email:    proto.String("user@mail.me"),
+		Password: proto.String("correct horse"),
+	}.Build())
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
@@ -0,0 +1,149 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+// Violation reports a field which breaks one of its rules. Field is the path
+// of the field from the validated message, e.g. "refresh_token.value".
+type Violation struct {
+	Field       string
+	Rule        string
+	Description string
+}
+
+// Error lists every Violation of a message.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Field + ": " + v.Description
+	}
+	return "invalid request: " + strings.Join(msgs, ", ")
+}
+
+// patterns caches the compiled pattern rules, keyed by their expression.
+var patterns sync.Map
+
+// Validate checks m against the rules declared on its fields in
+// api/validate.proto, descending into the messages it holds. It returns an
+// *Error listing every violation, or nil.
+func Validate(m proto.Message) error {
+	violations := validate(m.ProtoReflect(), "")
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+func validate(m protoreflect.Message, prefix string) []Violation {
+	violations := []Violation{}
+	desc := m.Descriptor()
+
+	oneofs := desc.Oneofs()
+	for i := range oneofs.Len() {
+		o := oneofs.Get(i)
+		rules, ok := proto.GetExtension(o.Options(), pb.E_OneofRules).(*pb.OneofRules)
+		if ok && rules.GetRequired() && m.WhichOneof(o) == nil {
+			violations = append(violations, Violation{
+				Field:       prefix + string(o.Name()),
+				Rule:        "required",
+				Description: "one of the fields must be set",
+			})
+		}
+	}
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+
+		rules, _ := proto.GetExtension(fd.Options(), pb.E_Rules).(*pb.FieldRules)
+		if !m.Has(fd) {
+			if rules.GetRequired() {
+				violations = append(violations, Violation{Field: path, Rule: "required", Description: "must be set"})
+			}
+			continue
+		}
+
+		v := m.Get(fd)
+		switch {
+		case fd.IsList() || fd.IsMap():
+		case fd.Kind() == protoreflect.MessageKind:
+			violations = append(violations, validate(v.Message(), path+".")...)
+		case rules != nil:
+			violations = append(violations, check(fd, v, rules, path)...)
+		}
+	}
+	return violations
+}
+
+func check(fd protoreflect.FieldDescriptor, v protoreflect.Value, rules *pb.FieldRules, path string) []Violation {
+	violations := []Violation{}
+	add := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{
+			Field:       path,
+			Rule:        rule,
+			Description: fmt.Sprintf(format, args...),
+		})
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s := v.String()
+		n := utf8.RuneCountInString(s)
+		if rules.GetRequired() && s == "" {
+			add("required", "must not be empty")
+			return violations
+		}
+		if rules.HasMinLen() && n < int(rules.GetMinLen()) {
+			add("min_len", "must be at least %d characters", rules.GetMinLen())
+		}
+		if rules.HasMaxLen() && n > int(rules.GetMaxLen()) {
+			add("max_len", "must be at most %d characters", rules.GetMaxLen())
+		}
+		if rules.HasPattern() && !match(rules.GetPattern(), s) {
+			add("pattern", "must match %s", rules.GetPattern())
+		}
+		if rules.GetEmail() && !isEmail(s) {
+			add("email", "must be an email address")
+		}
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+		n := v.Int()
+		if rules.HasGte() && n < rules.GetGte() {
+			add("gte", "must be greater than or equal to %d", rules.GetGte())
+		}
+		if rules.HasLte() && n > rules.GetLte() {
+			add("lte", "must be less than or equal to %d", rules.GetLte())
+		}
+	}
+	return violations
+}
+
+func match(pattern, s string) bool {
+	re, ok := patterns.Load(pattern)
+	if !ok {
+		re, _ = patterns.LoadOrStore(pattern, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+	return re.(*regexp.Regexp).MatchString(s)
+}
+
+// isEmail accepts a bare address, without a display name or angle brackets.
+func isEmail(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s
+}
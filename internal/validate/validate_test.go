@@ -0,0 +1,94 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gebhn/auth-service/api/pb"
+)
+
+func violationsHelper(t *testing.T, m proto.Message) map[string]string {
+	t.Helper()
+
+	err := Validate(m)
+	require.Error(t, err)
+
+	verr := err.(*Error)
+	got := map[string]string{}
+	for _, v := range verr.Violations {
+		got[v.Field] = v.Rule
+	}
+	return got
+}
+
+func TestValidate_Success(t *testing.T) {
+	for _, m := range []proto.Message{
+		pb.RegisterRequest_builder{
+			Username: proto.String("user.name-1"),
+			Email:    proto.String("user@mail.me"),
+			Password: proto.String("correct horse"),
+		}.Build(),
+		pb.LoginRequest_builder{Email: proto.String("user@mail.me"), Password: proto.String("pass")}.Build(),
+		pb.UpdateRequest_builder{Email: proto.String("user@mail.me")}.Build(),
+		pb.RefreshRequest_builder{RefreshToken: pb.Token_builder{Value: proto.String("token")}.Build()}.Build(),
+		&pb.ListSessionsRequest{},
+	} {
+		assert.NoError(t, Validate(m))
+	}
+}
+
+func TestValidate_Register(t *testing.T) {
+	got := violationsHelper(t, pb.RegisterRequest_builder{
+		Username: proto.String("a b"),
+		Email:    proto.String("Name <user@mail.me>"),
+	}.Build())
+
+	assert.Equal(t, map[string]string{
+		"username": "pattern",
+		"email":    "email",
+		"password": "required",
+	}, got)
+}
+
+func TestValidate_Lengths(t *testing.T) {
+	got := violationsHelper(t, pb.ChangePasswordRequest_builder{
+		OldPassword: proto.String(""),
+		NewPassword: proto.String(strings.Repeat("ü", 129)),
+	}.Build())
+
+	assert.Equal(t, map[string]string{"old_password": "required"}, got)
+
+	got = violationsHelper(t, pb.LoginRequest_builder{
+		Username: proto.String(strings.Repeat("ü", 33)),
+		Password: proto.String("pass"),
+	}.Build())
+	assert.Equal(t, map[string]string{"username": "max_len"}, got)
+
+	got = violationsHelper(t, pb.UpdateRequest_builder{Username: proto.String("ab")}.Build())
+	assert.Equal(t, map[string]string{"username": "min_len"}, got)
+}
+
+func TestValidate_Oneof(t *testing.T) {
+	got := violationsHelper(t, pb.LoginRequest_builder{Password: proto.String("pass")}.Build())
+	assert.Equal(t, map[string]string{"id": "required"}, got)
+}
+
+func TestValidate_Nested(t *testing.T) {
+	got := violationsHelper(t, pb.RefreshRequest_builder{RefreshToken: &pb.Token{}}.Build())
+	assert.Equal(t, map[string]string{"refresh_token.value": "required"}, got)
+
+	got = violationsHelper(t, &pb.AccessRequest{})
+	assert.Equal(t, map[string]string{"access_token": "required"}, got)
+}
+
+func TestValidate_Numbers(t *testing.T) {
+	got := violationsHelper(t, pb.ListSessionsRequest_builder{PageSize: proto.Int32(101)}.Build())
+	assert.Equal(t, map[string]string{"page_size": "lte"}, got)
+
+	got = violationsHelper(t, pb.UpdateRequest_builder{ExpectedVersion: proto.Int64(-1)}.Build())
+	assert.Equal(t, map[string]string{"expected_version": "gte"}, got)
+}